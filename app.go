@@ -1,11 +1,13 @@
 package summer
 
 import (
+	"context"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
 	"net/http/pprof"
 	"strings"
+	"sync"
 	"sync/atomic"
 )
 
@@ -20,20 +22,42 @@ type App[T Context] interface {
 	// Registry inherit [Registry]
 	Registry
 
-	// HandleFunc register an action function with given path pattern
+	// Group inherit [Group], routes registered on [App] directly are in the root group
+	Group[T]
+
+	// OpenAPI generate an OpenAPI 3.1 document from all registered routes, including routes of mounted [App]
+	//
+	// Request and response schemas are available for routes registered by [HandleTyped], fields with json tag
+	// prefixed by "path_", "query_" and "header_" are documented as parameters
+	OpenAPI() map[string]any
+
+	// Run listen and serve until ctx is done or a signal is received, see [RunOption]
 	//
-	// This function is similar with [http.ServeMux.HandleFunc]
-	HandleFunc(pattern string, fn HandlerFunc[T])
+	// Components are started with [Registry.Startup] before accepting requests. On stop, readiness check starts
+	// to fail, and after a pre-stop delay, the server stops accepting requests and drains in-flight ones,
+	// then components are shut down in reverse order with [Registry.Shutdown]
+	//
+	// Long-lived handlers are signaled while draining, context of [Context.SSE] handlers is canceled, and
+	// connections of [Context.Upgrade] are closed with [WebSocketCloseGoingAway], components are shut down
+	// after these handlers return
+	//
+	// It returns nil after a graceful shutdown, or errors of listening, startup, serving, draining and shutdown
+	Run(ctx context.Context, opts ...RunOption) error
 }
 
 type app[T Context] struct {
 	// before-init
 	Registry
 
+	*group[T]
+
 	cf   ContextFactory[T]
 	opts options
 
-	mux *http.ServeMux
+	router *router
+
+	routeDocs []routeDoc
+	mounts    []mountDoc
 
 	hMain http.Handler
 	hProm http.Handler
@@ -42,29 +66,34 @@ type app[T Context] struct {
 	cc chan struct{}
 
 	readinessFailed int64
+
+	// stopping is set to 1 once [App.Run] starts shutting down
+	stopping int32
+
+	// serving is set to 1 once the first request is served, freezing [Group.Use]
+	serving int32
+
+	drain *drainer
 }
 
-func (a *app[T]) HandleFunc(pattern string, fn HandlerFunc[T]) {
-	a.mux.Handle(
-		pattern,
-		otelhttp.WithRouteTag(
-			pattern,
-			http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-				c := a.cf(rw, req)
-				func() {
-					defer c.Perform()
-					a.Inject(c)
-					fn(c)
-				}()
-			}),
-		),
-	)
+// haltHandler create a [http.Handler] responding with a [HaltError] of given status code
+func (a *app[T]) haltHandler(code int) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		c := a.cf(rw, req)
+		defer c.Perform()
+		HaltString(strings.ToLower(http.StatusText(code)), HaltWithStatusCode(code))
+	})
 }
 
 func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	// alive, ready, metrics
 	if req.URL.Path == a.opts.readinessPath {
 		// readiness first, works when readinessPath == livenessPath
+		if atomic.LoadInt32(&a.stopping) != 0 {
+			// not counted as failure, liveness should not cascade while shutting down
+			respondInternal(rw, "STOPPING", http.StatusServiceUnavailable)
+			return
+		}
 		sb := &strings.Builder{}
 		var failed bool
 		a.Check(req.Context(), func(name string, err error) {
@@ -102,6 +131,9 @@ func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	} else if req.URL.Path == a.opts.metricsPath {
 		a.hProm.ServeHTTP(rw, req)
 		return
+	} else if a.opts.openAPIPath != "" && req.URL.Path == a.opts.openAPIPath {
+		a.serveOpenAPI(rw)
+		return
 	}
 
 	// pprof
@@ -110,28 +142,41 @@ func (a *app[T]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if atomic.LoadInt32(&a.serving) == 0 {
+		atomic.StoreInt32(&a.serving, 1)
+	}
+
+	ctx := req.Context()
+
 	// concurrency control
 	if a.cc != nil {
 		<-a.cc
-		defer func() {
-			a.cc <- struct{}{}
-		}()
+		// released early by long-lived connections, see [Context.Upgrade]
+		var once sync.Once
+		release := func() {
+			once.Do(func() {
+				a.cc <- struct{}{}
+			})
+		}
+		defer release()
+		ctx = context.WithValue(ctx, contextKeyConcurrencyRelease, release)
 	}
 
+	// long-lived handlers are drained by the outermost app, see [Group.Mount]
+	if drainerFromContext(ctx) == nil {
+		ctx = context.WithValue(ctx, contextKeyDrainer, a.drain)
+	}
+
+	// make options available to [Context]
+	req = req.WithContext(context.WithValue(ctx, contextKeyOptions, &a.opts))
+
 	a.hMain.ServeHTTP(rw, req)
 }
 
 // New create an [App] with a custom [ContextFactory] and additional [Option]
 func New[T Context](cf ContextFactory[T], opts ...Option) App[T] {
 	a := &app[T]{
-
-		opts: options{
-			concurrency:      128,
-			readinessCascade: 5,
-			readinessPath:    DefaultReadinessPath,
-			livenessPath:     DefaultLivenessPath,
-			metricsPath:      DefaultMetricsPath,
-		},
+		opts: defaultOptions(),
 	}
 
 	for _, opt := range opts {
@@ -142,9 +187,15 @@ func New[T Context](cf ContextFactory[T], opts ...Option) App[T] {
 
 	a.cf = cf
 
-	a.mux = &http.ServeMux{}
+	a.drain = newDrainer()
+
+	a.group = &group[T]{app: a}
+
+	a.router = newRouter()
+	a.router.notFound = a.haltHandler(http.StatusNotFound)
+	a.router.methodNotAllowed = a.haltHandler(http.StatusMethodNotAllowed)
 
-	a.hMain = otelhttp.NewHandler(a.mux, "http")
+	a.hMain = otelhttp.NewHandler(a.router, "http")
 	a.hProm = promhttp.Handler()
 	m := &http.ServeMux{}
 	m.HandleFunc("/debug/pprof/", pprof.Index)
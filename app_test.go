@@ -66,3 +66,100 @@ func TestApp(t *testing.T) {
 	a.ServeHTTP(rw, req)
 
 }
+
+func TestAppRouter(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("GET /users/{id}", func(c Context) {
+		args := Bind[struct {
+			ID string `json:"path_id"`
+		}](c)
+		c.Text(args.ID)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/users/42", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "42", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/users/42", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	require.Equal(t, "GET, HEAD", rw.Header().Get("Allow"))
+	require.Equal(t, `{"message":"method not allowed"}`, rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/posts/42", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	require.Equal(t, `{"message":"not found"}`, rw.Body.String())
+}
+
+func TestAppMiddleware(t *testing.T) {
+	a := Basic()
+
+	var trace []string
+
+	mw := func(name string) Middleware[Context] {
+		return func(h HandlerFunc[Context]) HandlerFunc[Context] {
+			return func(c Context) {
+				trace = append(trace, name+"-in")
+				if c.Req().URL.Query().Get("deny") == name {
+					HaltString("denied by "+name, HaltWithStatusCode(http.StatusForbidden))
+				}
+				h(c)
+				trace = append(trace, name+"-out")
+			}
+		}
+	}
+
+	a.Use(mw("app-1"))
+	a.HandleFunc("/test", func(c Context) {
+		trace = append(trace, "handler")
+		c.Text("OK")
+	}, mw("route-1"), mw("route-2"))
+	a.Use(mw("app-2"))
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, []string{
+		"app-1-in", "app-2-in", "route-1-in", "route-2-in",
+		"handler",
+		"route-2-out", "route-1-out", "app-2-out", "app-1-out",
+	}, trace)
+
+	trace = nil
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test?deny=route-1", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusForbidden, rw.Code)
+	require.Equal(t, `{"message":"denied by route-1"}`, rw.Body.String())
+	require.Equal(t, []string{"app-1-in", "app-2-in", "route-1-in"}, trace)
+}
+
+func TestAppMiddlewareComposedOnce(t *testing.T) {
+	a := Basic()
+
+	var created, served int
+	a.Use(func(h HandlerFunc[Context]) HandlerFunc[Context] {
+		created++
+		return func(c Context) {
+			served++
+			h(c)
+		}
+	})
+	a.HandleFunc("/test", func(c Context) {
+		c.Text("OK")
+	})
+
+	for i := 0; i < 3; i++ {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test", nil)
+		a.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code)
+	}
+	require.Equal(t, 1, created)
+	require.Equal(t, 3, served)
+
+	require.PanicsWithValue(t, "middlewares must be registered before serving requests", func() {
+		a.Use(func(h HandlerFunc[Context]) HandlerFunc[Context] { return h })
+	})
+}
@@ -0,0 +1,623 @@
+package summer
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bindSource request data collected for [Context.Bind]
+type bindSource struct {
+	header http.Header
+	path   map[string]string
+	query  url.Values
+
+	// parsed lazily from header
+	cookies []*http.Cookie
+
+	// body decoded by a [DecoderFunc]
+	body map[string]any
+	// raw JSON body, decoded directly into the target
+	json []byte
+}
+
+// readRequest collect request data for binding, body is consumed
+func readRequest(req *http.Request, opts *options) (src *bindSource, form *multipart.Form, err error) {
+	src = &bindSource{
+		header: req.Header,
+		path:   pathParamsFromContext(req.Context()),
+		query:  req.URL.Query(),
+	}
+
+	// multipart, streaming
+	if contentType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); contentType == ContentTypeMultipartForm {
+		body := req.Body
+		if opts.multipartMaxDisk > 0 {
+			body = http.MaxBytesReader(nil, body, opts.multipartMaxMemory+opts.multipartMaxDisk)
+		}
+		if form, err = multipart.NewReader(body, params["boundary"]).ReadForm(opts.multipartMaxMemory); err != nil {
+			// mime/multipart may not wrap error from the underlying reader, take it directly
+			var mbe *http.MaxBytesError
+			if _, err1 := body.Read(nil); errors.As(err1, &mbe) {
+				err = NewHaltError(err1, HaltWithStatusCode(http.StatusRequestEntityTooLarge))
+			}
+			return
+		}
+		src.body = map[string]any{}
+		for k, vs := range form.Value {
+			src.body[k] = flattenSingleSlice(vs)
+		}
+		return
+	}
+
+	// body
+	body := req.Body
+	if opts.maxBodySize > 0 {
+		body = http.MaxBytesReader(nil, body, opts.maxBodySize)
+	}
+	var buf []byte
+	if buf, err = io.ReadAll(body); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			err = NewHaltError(err, HaltWithStatusCode(http.StatusRequestEntityTooLarge))
+		}
+		return
+	}
+
+	if len(buf) == 0 {
+		return
+	}
+
+	var contentType string
+	if contentType, _, err = mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil {
+		return
+	}
+
+	d, ok := lookupDecoder(opts.decoders, contentType)
+	if !ok {
+		err = NewHaltError(
+			errors.New("unsupported request body type: "+contentType),
+			HaltWithStatusCode(http.StatusUnsupportedMediaType),
+		)
+		return
+	}
+
+	// built-in JSON decoder, skip the intermediate map
+	if d.rawJSON {
+		src.json = buf
+		return
+	}
+
+	src.body = map[string]any{}
+	err = d.fn(buf, src.body)
+	return
+}
+
+// flatten put all data into a single map, header is prefixed with "header_", path with "path_",
+// query with both "query_" and no prefix, cookie with "cookie_", and body is merged at last
+func (src *bindSource) flatten(m map[string]any) (err error) {
+	for k, vs := range src.header {
+		k = "header_" + strings.ToLower(strings.ReplaceAll(k, "-", "_"))
+		m[k] = flattenSingleSlice(vs)
+	}
+	for k, v := range src.path {
+		m["path_"+k] = v
+	}
+	for k, vs := range src.query {
+		v := flattenSingleSlice(vs)
+		m[k] = v
+		m["query_"+k] = v
+	}
+	for _, c := range src.allCookies() {
+		m["cookie_"+c.Name] = c.Value
+	}
+	if src.json != nil {
+		if err = DecodeJSON(src.json, m); err != nil {
+			return
+		}
+	}
+	for k, v := range src.body {
+		m[k] = v
+	}
+	return
+}
+
+// bindJSONRoundTrip bind by flattening all data into a map, and marshalling and unmarshalling it as JSON,
+// used for targets other than struct
+func bindJSONRoundTrip(src *bindSource, data any) (err error) {
+	m := map[string]any{}
+	if err = src.flatten(m); err != nil {
+		return
+	}
+	var buf []byte
+	if buf, err = json.Marshal(m); err != nil {
+		return
+	}
+	return json.Unmarshal(buf, data)
+}
+
+type bindSourceKind int
+
+const (
+	bindSourceNone bindSourceKind = iota
+	bindSourceHeader
+	bindSourcePath
+	bindSourceQuery
+	bindSourceCookie
+	bindSourceBody
+)
+
+var (
+	bindSourceNames = map[string]bindSourceKind{
+		"header": bindSourceHeader,
+		"path":   bindSourcePath,
+		"query":  bindSourceQuery,
+		"cookie": bindSourceCookie,
+		"body":   bindSourceBody,
+	}
+
+	bindSourcePrefixes = []struct {
+		prefix string
+		source bindSourceKind
+	}{
+		{"header_", bindSourceHeader},
+		{"path_", bindSourcePath},
+		{"query_", bindSourceQuery},
+		{"cookie_", bindSourceCookie},
+	}
+)
+
+type bindField struct {
+	jsonField
+	source bindSourceKind
+	// key in source, canonical header key for header
+	key string
+	// normalized header name, like "x_tenant"
+	headerName string
+	// value from "default" tag
+	defaultValue string
+	hasDefault   bool
+}
+
+// newBindField resolve source and key of a field from json name prefix and "bind" tag, panics on invalid tags
+func newBindField(t reflect.Type, jf jsonField) bindField {
+	f := bindField{jsonField: jf, key: jf.name}
+
+	for _, item := range bindSourcePrefixes {
+		if strings.HasPrefix(jf.name, item.prefix) {
+			f.source = item.source
+			f.key = strings.TrimPrefix(jf.name, item.prefix)
+			break
+		}
+	}
+
+	tag := t.FieldByIndex(jf.index).Tag
+
+	if name := tag.Get("bind"); name != "" {
+		source, ok := bindSourceNames[name]
+		if !ok {
+			panic(t.String() + "." + jf.name + ": unknown bind source: " + name)
+		}
+		f.source = source
+	}
+
+	if f.source == bindSourceHeader {
+		f.headerName = f.key
+		f.key = textproto.CanonicalMIMEHeaderKey(strings.ReplaceAll(f.headerName, "_", "-"))
+	}
+
+	f.defaultValue, f.hasDefault = tag.Lookup("default")
+
+	if f.hasDefault {
+		if err := setFromString(reflect.New(jf.typ).Elem(), f.defaultValue); err != nil {
+			panic(t.String() + "." + jf.name + ": invalid default value: " + err.Error())
+		}
+	}
+
+	return f
+}
+
+var structBindFields sync.Map
+
+// bindFieldsOf returns cached bind fields of a struct type
+func bindFieldsOf(t reflect.Type) []bindField {
+	if v, ok := structBindFields.Load(t); ok {
+		return v.([]bindField)
+	}
+	var fields []bindField
+	for _, jf := range jsonFieldsOf(t) {
+		fields = append(fields, newBindField(t, jf))
+	}
+	v, _ := structBindFields.LoadOrStore(t, fields)
+	return v.([]bindField)
+}
+
+// MustCheckTags check "bind", "default" and "validate" tags of T and nested structs, panics on invalid tags
+//
+// Tags are checked on the first request binding T otherwise, call it at startup for types used with [Bind],
+// [HandleTyped] does it for request types on registration
+func MustCheckTags[T any]() {
+	checkTags(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// checkTags check tags of a type like [MustCheckTags], fields are cached for later binding and validation
+func checkTags(t reflect.Type) {
+	if t.Kind() == reflect.Struct {
+		bindFieldsOf(t)
+	}
+	checkValidationTags(t, map[reflect.Type]bool{})
+}
+
+// checkValidationTags check "validate" tags of struct types reachable by [Validate] from t
+func checkValidationTags(t reflect.Type, visited map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == typeTime || visited[t] {
+		return
+	}
+	visited[t] = true
+	for _, f := range validationFieldsOf(t) {
+		checkValidationTags(f.typ, visited)
+	}
+}
+
+// headerValues find header values by canonical key, or by normalized name as a fallback
+func (src *bindSource) headerValues(f *bindField) []string {
+	if vs, ok := src.header[f.key]; ok {
+		return vs
+	}
+	for k, vs := range src.header {
+		if strings.ToLower(strings.ReplaceAll(k, "-", "_")) == f.headerName {
+			return vs
+		}
+	}
+	return nil
+}
+
+// allCookies returns cookies parsed from header
+func (src *bindSource) allCookies() []*http.Cookie {
+	if src.cookies == nil {
+		src.cookies = (&http.Request{Header: src.header}).Cookies()
+	}
+	return src.cookies
+}
+
+// cookieValues find cookie values by name
+func (src *bindSource) cookieValues(name string) (vs []string) {
+	for _, c := range src.allCookies() {
+		if c.Name == name {
+			vs = append(vs, c.Value)
+		}
+	}
+	return
+}
+
+// queryValues find query values by key, or case-insensitively as a fallback, the same way [json.Unmarshal] matches keys
+func (src *bindSource) queryValues(key string) []string {
+	if vs, ok := src.query[key]; ok {
+		return vs
+	}
+	for k, vs := range src.query {
+		if strings.EqualFold(k, key) {
+			return vs
+		}
+	}
+	return nil
+}
+
+// values find string values of a field pinned to a source
+func (src *bindSource) values(f *bindField) []string {
+	switch f.source {
+	case bindSourceHeader:
+		return src.headerValues(f)
+	case bindSourcePath:
+		if v, ok := src.path[f.key]; ok {
+			return []string{v}
+		}
+	case bindSourceQuery:
+		return src.queryValues(f.key)
+	case bindSourceCookie:
+		return src.cookieValues(f.key)
+	}
+	return nil
+}
+
+// bindRequest bind request data into data
+//
+// Pointer to struct is bound field by field without intermediate JSON, other targets fall back to a JSON round-trip.
+//
+// A field prefixed with "header_", "path_", "query_" or "cookie_", or with a "bind" tag of "header", "path", "query" or
+// "cookie", is pinned to that source, and never set by other sources.
+//
+// A field with "bind" tag of "body" is only set by body.
+//
+// Other fields are set by query, then overridden by body.
+//
+// Query keys are matched exactly, then case-insensitively, like keys of JSON body.
+//
+// Value of "default" tag is applied before all sources.
+func bindRequest(src *bindSource, data any) (err error) {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return bindJSONRoundTrip(src, data)
+	}
+	rv = rv.Elem()
+
+	fields := bindFieldsOf(rv.Type())
+
+	set := func(f *bindField, fn func(fv reflect.Value) error) (err error) {
+		var fv reflect.Value
+		if fv, err = fieldByIndexAlloc(rv, f.index); err != nil {
+			return
+		}
+		if err = fn(fv); err != nil {
+			return bindFieldError(f.name, err)
+		}
+		return
+	}
+
+	// default, and query for fields not pinned
+	for i := range fields {
+		f := &fields[i]
+
+		if f.hasDefault {
+			if err = set(f, func(fv reflect.Value) error {
+				return setFromString(fv, f.defaultValue)
+			}); err != nil {
+				return
+			}
+		}
+
+		if f.source != bindSourceNone {
+			continue
+		}
+
+		if vs := src.queryValues(f.key); len(vs) > 0 {
+			if err = set(f, func(fv reflect.Value) error {
+				return setFromStrings(fv, vs)
+			}); err != nil {
+				return
+			}
+		}
+	}
+
+	// body, JSON
+	if src.json != nil {
+		var buf []byte
+		if buf, err = dropPinnedJSONKeys(src.json, fields); err != nil {
+			return
+		}
+		if err = json.Unmarshal(buf, data); err != nil {
+			return
+		}
+	}
+
+	// pinned sources
+	for i := range fields {
+		f := &fields[i]
+
+		if f.source == bindSourceNone || f.source == bindSourceBody {
+			continue
+		}
+
+		if vs := src.values(f); len(vs) > 0 {
+			if err = set(f, func(fv reflect.Value) error {
+				return setFromStrings(fv, vs)
+			}); err != nil {
+				return
+			}
+		}
+	}
+
+	// body, decoded
+	if src.body != nil {
+		for i := range fields {
+			f := &fields[i]
+
+			if f.source != bindSourceNone && f.source != bindSourceBody {
+				continue
+			}
+
+			if v, ok := src.body[f.name]; ok {
+				if err = set(f, func(fv reflect.Value) error {
+					return setFromAny(fv, v)
+				}); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// dropPinnedJSONKeys returns JSON body without keys of fields pinned to other sources, keys are matched
+// case-insensitively like [json.Unmarshal], body is returned as is if no key is dropped
+func dropPinnedJSONKeys(buf []byte, fields []bindField) ([]byte, error) {
+	var pinned []string
+	for i := range fields {
+		if f := &fields[i]; f.source != bindSourceNone && f.source != bindSourceBody {
+			pinned = append(pinned, f.name)
+		}
+	}
+	if len(pinned) == 0 {
+		return buf, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+
+	var dropped bool
+	for k := range m {
+		for _, name := range pinned {
+			if strings.EqualFold(k, name) {
+				delete(m, k)
+				dropped = true
+				break
+			}
+		}
+	}
+	if !dropped {
+		return buf, nil
+	}
+	return json.Marshal(m)
+}
+
+func bindFieldError(name string, err error) error {
+	return fmt.Errorf("invalid value for \"%s\": %w", name, err)
+}
+
+// fieldByIndexAlloc like [reflect.Value.FieldByIndex], but allocates nil embedded pointers
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, errors.New("cannot set embedded pointer to unexported struct: " + v.Type().Elem().String())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+var (
+	typeDuration        = reflect.TypeOf(time.Duration(0))
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setFromStrings set a field from string values of header, query or form
+func setFromStrings(v reflect.Value, vs []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 && !reflect.PointerTo(v.Type()).Implements(typeTextUnmarshaler) {
+		s := reflect.MakeSlice(v.Type(), len(vs), len(vs))
+		for i, item := range vs {
+			if err := setFromString(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setFromString(v, vs[0])
+}
+
+// setFromString set a field from a string, with conversion to bool, numbers, [time.Duration] and [time.Time]
+func setFromString(v reflect.Value, s string) (err error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+	case typeDuration:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err != nil {
+			return
+		}
+		v.SetInt(int64(d))
+		return
+	case typeTime:
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			if t, err = time.Parse("2006-01-02", s); err != nil {
+				return
+			}
+		}
+		v.Set(reflect.ValueOf(t))
+		return
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(typeTextUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err != nil {
+			return
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, v.Type().Bits()); err != nil {
+			return
+		}
+		v.SetFloat(n)
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return errors.New("cannot bind string to " + v.Type().String())
+		}
+		v.Set(reflect.ValueOf(s))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return
+		}
+		fallthrough
+	default:
+		// struct, map and others, try JSON
+		err = json.Unmarshal([]byte(s), v.Addr().Interface())
+	}
+	return
+}
+
+// setFromAny set a field from a value decoded by [DecoderFunc]
+func setFromAny(v reflect.Value, x any) error {
+	switch t := x.(type) {
+	case nil:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case string:
+		return setFromString(v, t)
+	case []string:
+		return setFromStrings(v, t)
+	}
+
+	xv := reflect.ValueOf(x)
+	if xv.Type().AssignableTo(v.Type()) {
+		v.Set(xv)
+		return nil
+	}
+
+	// fallback to JSON for numbers, nested maps and slices
+	buf, err := json.Marshal(x)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v.Addr().Interface())
+}
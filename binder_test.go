@@ -0,0 +1,345 @@
+package summer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type BinderTestEmbedded struct {
+	Trace string `json:"header_x_trace_id"`
+}
+
+type binderTestRequest struct {
+	*BinderTestEmbedded
+
+	ID       int64          `json:"path_id"`
+	Tenant   string         `json:"header_x_tenant"`
+	Limit    int            `json:"query_limit"`
+	Debug    bool           `json:"debug"`
+	Timeout  time.Duration  `json:"timeout"`
+	Since    time.Time      `json:"since"`
+	Tags     []string       `json:"tags"`
+	Ratio    *float64       `json:"ratio"`
+	Name     string         `json:"name"`
+	Age      int            `json:"age,string"`
+	Nested   map[string]any `json:"nested"`
+	Ignored  string         `json:"-"`
+	internal string
+}
+
+func newBinderTestRequest() *http.Request {
+	req := httptest.NewRequest(
+		"POST",
+		"https://example.com/users/42?limit=10&debug=true&timeout=1m30s&since=2023-01-02&tags=a&tags=b&ratio=0.5&name=query",
+		bytes.NewReader([]byte(`{"name":"body","age":"18","nested":{"a":1}}`)),
+	)
+	req.Header.Set("Content-Type", ContentTypeApplicationJSONUTF8)
+	req.Header.Set("X-Tenant", "t1")
+	req.Header.Set("X-Trace-Id", "trace")
+	return req.WithContext(context.WithValue(req.Context(), contextKeyPathParams, map[string]string{"id": "42"}))
+}
+
+func TestBindRequest(t *testing.T) {
+	src, _, err := readRequest(newBinderTestRequest(), optionsFromContext(context.Background()))
+	require.NoError(t, err)
+
+	var r binderTestRequest
+	require.NoError(t, bindRequest(src, &r))
+
+	ratio := 0.5
+	require.Equal(t, binderTestRequest{
+		BinderTestEmbedded: &BinderTestEmbedded{Trace: "trace"},
+		ID:                 42,
+		Tenant:             "t1",
+		Limit:              10,
+		Debug:              true,
+		Timeout:            time.Minute + 30*time.Second,
+		Since:              time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Tags:               []string{"a", "b"},
+		Ratio:              &ratio,
+		Name:               "body",
+		Age:                18,
+		Nested:             map[string]any{"a": float64(1)},
+	}, r)
+
+	// non-struct target falls back to JSON round-trip
+	var m map[string]any
+	require.NoError(t, bindRequest(src, &m))
+	require.Equal(t, "body", m["name"])
+	require.Equal(t, "t1", m["header_x_tenant"])
+	require.Equal(t, "42", m["path_id"])
+	require.Equal(t, "10", m["query_limit"])
+}
+
+func TestBindRequestForm(t *testing.T) {
+	req := httptest.NewRequest("POST", "https://example.com/test?name=query", bytes.NewReader([]byte(`name=form&age=18&tags=a&tags=b`)))
+	req.Header.Set("Content-Type", ContentTypeFormURLEncoded)
+	req.Header.Set("X_Legacy", "legacy")
+
+	src, _, err := readRequest(req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+
+	var r struct {
+		Name   string   `json:"name"`
+		Age    int      `json:"age"`
+		Tags   []string `json:"tags"`
+		Legacy string   `json:"header_x_legacy"`
+	}
+	require.NoError(t, bindRequest(src, &r))
+	require.Equal(t, "form", r.Name)
+	require.Equal(t, 18, r.Age)
+	require.Equal(t, []string{"a", "b"}, r.Tags)
+	require.Equal(t, "legacy", r.Legacy)
+}
+
+func TestBindRequestInvalid(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(c Context) {
+		Bind[struct {
+			Limit int `json:"query_limit"`
+		}](c)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test?limit=abc", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Contains(t, rw.Body.String(), `invalid value for \"query_limit\"`)
+}
+
+// binderBenchRequest bindable by both [bindRequest] and [bindJSONRoundTrip], numbers from query need ",string"
+// for the round-trip
+type binderBenchRequest struct {
+	ID     string         `json:"path_id"`
+	Tenant string         `json:"header_x_tenant"`
+	Limit  int            `json:"query_limit,string"`
+	Debug  bool           `json:"debug,string"`
+	Name   string         `json:"name"`
+	Age    int            `json:"age,string"`
+	Nested map[string]any `json:"nested"`
+}
+
+func TestBindBenchRequest(t *testing.T) {
+	opts := optionsFromContext(context.Background())
+
+	src, _, err := readRequest(newBinderTestRequest(), opts)
+	require.NoError(t, err)
+	var direct binderBenchRequest
+	require.NoError(t, bindRequest(src, &direct))
+
+	src, _, err = readRequest(newBinderTestRequest(), opts)
+	require.NoError(t, err)
+	var roundTrip binderBenchRequest
+	require.NoError(t, bindJSONRoundTrip(src, &roundTrip))
+
+	require.Equal(t, roundTrip, direct)
+}
+
+func BenchmarkBindDirect(b *testing.B) {
+	opts := optionsFromContext(context.Background())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src, _, _ := readRequest(newBinderTestRequest(), opts)
+		var r binderBenchRequest
+		if err := bindRequest(src, &r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindJSONRoundTrip(b *testing.B) {
+	opts := optionsFromContext(context.Background())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src, _, _ := readRequest(newBinderTestRequest(), opts)
+		var r binderBenchRequest
+		if err := bindJSONRoundTrip(src, &r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBindRequestPinning(t *testing.T) {
+	req := httptest.NewRequest(
+		"POST",
+		"https://example.com/test?name=query&role=query&size=5",
+		bytes.NewReader([]byte(`{"name":"body","role":"body","header_x_tenant":"evil","tenant":"evil","session":"evil"}`)),
+	)
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.Header.Set("X-Tenant", "t1")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+	src, _, err := readRequest(req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+
+	var r struct {
+		Name     string `json:"name" bind:"query"`
+		Role     string `json:"role" bind:"body"`
+		Tenant   string `json:"header_x_tenant"`
+		Tenant2  string `json:"tenant" bind:"header"`
+		Session  string `json:"session" bind:"cookie"`
+		Page     int    `json:"page" default:"1"`
+		Size     int    `json:"size" default:"10"`
+		Sort     string `json:"sort" bind:"query" default:"id"`
+		Missing  string `json:"missing" bind:"header" default:"none"`
+		Optional string `json:"optional"`
+	}
+	require.NoError(t, bindRequest(src, &r))
+	require.Equal(t, "query", r.Name)
+	require.Equal(t, "body", r.Role)
+	require.Equal(t, "t1", r.Tenant)
+	require.Equal(t, "", r.Tenant2)
+	require.Equal(t, "s1", r.Session)
+	require.Equal(t, 1, r.Page)
+	require.Equal(t, 5, r.Size)
+	require.Equal(t, "id", r.Sort)
+	require.Equal(t, "none", r.Missing)
+
+	require.Panics(t, func() {
+		bindFieldsOf(reflect.TypeOf(struct {
+			A string `json:"a" bind:"unknown"`
+		}{}))
+	})
+	require.Panics(t, func() {
+		bindFieldsOf(reflect.TypeOf(struct {
+			A int `json:"a" default:"x"`
+		}{}))
+	})
+}
+
+func TestBindRequestPinnedJSONKeys(t *testing.T) {
+	req := httptest.NewRequest(
+		"POST",
+		"https://example.com/test",
+		bytes.NewReader([]byte(`{"name":"a","header_x_tenant":123,"HEADER_X_TRACE_ID":"evil"}`)),
+	)
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.Header.Set("X-Tenant", "t1")
+
+	src, _, err := readRequest(req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+
+	var r struct {
+		*BinderTestEmbedded
+		Name   string `json:"name"`
+		Tenant string `json:"header_x_tenant"`
+	}
+	require.NoError(t, bindRequest(src, &r))
+	require.Equal(t, "a", r.Name)
+	require.Equal(t, "t1", r.Tenant)
+	// pinned keys in body are ignored, nothing to set for embedded struct
+	require.Nil(t, r.BinderTestEmbedded)
+
+	// body is decoded as is when nothing is pinned
+	buf := []byte(`{"name":"a"}`)
+	out, err := dropPinnedJSONKeys(buf, bindFieldsOf(reflect.TypeOf(r)))
+	require.NoError(t, err)
+	require.Equal(t, buf, out)
+}
+
+// readRequestFlatten put all request data into a single map with [readRequest] and [bindSource.flatten]
+func readRequestFlatten(m map[string]any, req *http.Request, opts *options) (form *multipart.Form, err error) {
+	var src *bindSource
+	if src, form, err = readRequest(req, opts); err != nil {
+		return
+	}
+	err = src.flatten(m)
+	return
+}
+
+func TestReadRequestFlatten(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/get?aaa=bbb", nil)
+
+	m := map[string]any{}
+	_, err := readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "query_aaa": "bbb"}, m)
+
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`{"hello":"world"}`)))
+	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+
+	m = map[string]any{}
+	_, err = readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "application/json;charset=utf-8", "hello": "world", "query_aaa": "bbb"}, m)
+
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`hello=world`)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+
+	m = map[string]any{}
+	_, err = readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "application/x-www-form-urlencoded;charset=utf-8", "hello": "world", "query_aaa": "bbb"}, m)
+
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`hello=world`)))
+	req.Header.Set("Content-Type", "text/plain;charset=utf-8")
+
+	m = map[string]any{}
+	_, err = readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": "text/plain;charset=utf-8", "query_aaa": "bbb", "text": "hello=world"}, m)
+
+	req = httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader([]byte(`hello=world`)))
+	req.Header.Set("Content-Type", "application/x-custom")
+
+	m = map[string]any{}
+	_, err = readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnsupportedMediaType, StatusCodeFromError(err))
+}
+
+func TestReadRequestFlattenMultipart(t *testing.T) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	require.NoError(t, mw.WriteField("hello", "world"))
+	fw, err := mw.CreateFormFile("upload", "test.txt")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("content"))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "https://example.com/post?aaa=bbb", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	m := map[string]any{}
+	form, err := readRequestFlatten(m, req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"aaa": "bbb", "header_content_type": mw.FormDataContentType(), "hello": "world", "query_aaa": "bbb"}, m)
+	require.Len(t, form.File["upload"], 1)
+	require.Equal(t, "test.txt", form.File["upload"][0].Filename)
+
+	opts := defaultOptions()
+	opts.multipartMaxMemory = 4
+	opts.multipartMaxDisk = 4
+
+	req = httptest.NewRequest("POST", "https://example.com/post", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	_, err = readRequestFlatten(map[string]any{}, req, &opts)
+	var mbe *http.MaxBytesError
+	require.ErrorAs(t, err, &mbe)
+	require.Equal(t, http.StatusRequestEntityTooLarge, StatusCodeFromError(err))
+}
+
+func TestBindRequestQueryCaseInsensitive(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/test?Name=bob&LIMIT=5&role=exact&Role=fold", nil)
+	src, _, err := readRequest(req, optionsFromContext(req.Context()))
+	require.NoError(t, err)
+
+	var r struct {
+		Name  string `json:"name"`
+		Limit int    `json:"query_limit"`
+		Role  string `json:"role"`
+	}
+	require.NoError(t, bindRequest(src, &r))
+	require.Equal(t, "bob", r.Name)
+	require.Equal(t, 5, r.Limit)
+	// exact match wins
+	require.Equal(t, "exact", r.Role)
+}
@@ -0,0 +1,230 @@
+package summer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressWriter compresses data written to it, Flush writes pending data to the underlying writer,
+// and Close completes the compressed stream without closing the underlying writer
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// CompressorFunc create a [CompressWriter] writing to w, for response compression, see [WithCompressor]
+type CompressorFunc func(w io.Writer) (CompressWriter, error)
+
+type compressor struct {
+	coding string
+	fn     CompressorFunc
+}
+
+var (
+	gzipWriterPool sync.Pool
+	zlibWriterPool sync.Pool
+)
+
+// pooledGzipWriter puts the writer back to gzipWriterPool on Close, only once
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w *pooledGzipWriter) Close() error {
+	if w.Writer == nil {
+		return nil
+	}
+	err := w.Writer.Close()
+	gzipWriterPool.Put(w.Writer)
+	w.Writer = nil
+	return err
+}
+
+// pooledZlibWriter puts the writer back to zlibWriterPool on Close, only once
+type pooledZlibWriter struct {
+	*zlib.Writer
+}
+
+func (w *pooledZlibWriter) Close() error {
+	if w.Writer == nil {
+		return nil
+	}
+	err := w.Writer.Close()
+	zlibWriterPool.Put(w.Writer)
+	w.Writer = nil
+	return err
+}
+
+// CompressGzip compressor for content coding "gzip"
+func CompressGzip(w io.Writer) (CompressWriter, error) {
+	if gw, ok := gzipWriterPool.Get().(*gzip.Writer); ok {
+		gw.Reset(w)
+		return &pooledGzipWriter{gw}, nil
+	}
+	return &pooledGzipWriter{gzip.NewWriter(w)}, nil
+}
+
+// CompressDeflate compressor for content coding "deflate", which is zlib format actually
+func CompressDeflate(w io.Writer) (CompressWriter, error) {
+	if zw, ok := zlibWriterPool.Get().(*zlib.Writer); ok {
+		zw.Reset(w)
+		return &pooledZlibWriter{zw}, nil
+	}
+	return &pooledZlibWriter{zlib.NewWriter(w)}, nil
+}
+
+func defaultCompressors() []compressor {
+	return []compressor{
+		{coding: "gzip", fn: CompressGzip},
+		{coding: "deflate", fn: CompressDeflate},
+	}
+}
+
+// acceptEncodingQ returns quality value of a content coding in Accept-Encoding header, 0 if not acceptable
+func acceptEncodingQ(header string, coding string) float64 {
+	wildcard := 0.0
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		name = strings.TrimSpace(name)
+		q := 1.0
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = f
+			}
+		}
+		if strings.EqualFold(name, coding) {
+			return q
+		}
+		if name == "*" {
+			wildcard = q
+		}
+	}
+	return wildcard
+}
+
+// acceptsEncoding check if a content coding is acceptable by Accept-Encoding header
+func acceptsEncoding(header string, coding string) bool {
+	return acceptEncodingQ(header, coding) > 0
+}
+
+// negotiateCompressor pick the compressor with the highest quality value, ties are broken by order of compressors
+func negotiateCompressor(compressors []compressor, header string) (c compressor, ok bool) {
+	if header == "" {
+		return
+	}
+	var best float64
+	for _, item := range compressors {
+		if q := acceptEncodingQ(header, item.coding); q > best {
+			c, ok, best = item, true, q
+		}
+	}
+	return
+}
+
+// compressibleType check if content type is worth compressing, media types already compressed are skipped
+func compressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "font/woff"),
+		mediaType == ContentTypeTextEventStream:
+		return false
+	}
+	switch mediaType {
+	case "application/zip",
+		"application/gzip",
+		"application/x-gzip",
+		"application/zstd",
+		"application/x-bzip2",
+		"application/x-xz",
+		"application/x-7z-compressed",
+		"application/x-rar-compressed",
+		"application/octet-stream":
+		return false
+	}
+	return true
+}
+
+// compressible check if response can be compressed, Vary header is set if so
+func (c *basicContext) compressible() bool {
+	if c.opts.compressionMinSize < 0 || len(c.opts.compressors) == 0 {
+		return false
+	}
+	if c.code < http.StatusOK || c.code == http.StatusNoContent || c.code == http.StatusNotModified {
+		return false
+	}
+	h := c.rw.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" || !compressibleType(h.Get("Content-Type")) {
+		return false
+	}
+	addVary(h, "Accept-Encoding")
+	return true
+}
+
+// markCompressed set headers for a compressed response, a strong ETag is weakened since bytes are changed
+func (c *basicContext) markCompressed(coding string) {
+	h := c.rw.Header()
+	h.Set("Content-Encoding", coding)
+	if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		h.Set("ETag", "W/"+etag)
+	}
+}
+
+// compressBody compress the buffered response body if acceptable by client
+func (c *basicContext) compressBody() {
+	if len(c.body) < c.opts.compressionMinSize || !c.compressible() {
+		return
+	}
+	cp, ok := negotiateCompressor(c.opts.compressors, c.req.Header.Get("Accept-Encoding"))
+	if !ok {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := cp.fn(buf)
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(c.body); err != nil {
+		_ = w.Close()
+		return
+	}
+	if err = w.Close(); err != nil || buf.Len() >= len(c.body) {
+		return
+	}
+
+	c.markCompressed(cp.coding)
+	c.rw.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	c.body = buf.Bytes()
+}
+
+// compressStream create a [CompressWriter] for a streaming response if acceptable by client, headers are not sent yet
+func (c *basicContext) compressStream() CompressWriter {
+	if !c.compressible() {
+		return nil
+	}
+	cp, ok := negotiateCompressor(c.opts.compressors, c.req.Header.Get("Accept-Encoding"))
+	if !ok {
+		return nil
+	}
+	w, err := cp.fn(c.rw)
+	if err != nil {
+		return nil
+	}
+	c.markCompressed(cp.coding)
+	return w
+}
@@ -0,0 +1,188 @@
+package summer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testCompressWriter struct {
+	io.Writer
+}
+
+func (w testCompressWriter) Close() error {
+	return nil
+}
+
+func (w testCompressWriter) Flush() error {
+	return nil
+}
+
+func TestNegotiateCompressor(t *testing.T) {
+	compressors := defaultCompressors()
+
+	_, ok := negotiateCompressor(compressors, "")
+	require.False(t, ok)
+
+	cp, ok := negotiateCompressor(compressors, "deflate, gzip")
+	require.True(t, ok)
+	require.Equal(t, "gzip", cp.coding)
+
+	cp, ok = negotiateCompressor(compressors, "gzip;q=0.5, deflate")
+	require.True(t, ok)
+	require.Equal(t, "deflate", cp.coding)
+
+	_, ok = negotiateCompressor(compressors, "br, gzip;q=0, *;q=0")
+	require.False(t, ok)
+}
+
+func TestCompressWriterCloseTwice(t *testing.T) {
+	for _, fn := range []CompressorFunc{CompressGzip, CompressDeflate} {
+		w, err := fn(io.Discard)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, w.Close())
+
+		// put back to the pool only once, never shared by two writers
+		w1, err := fn(io.Discard)
+		require.NoError(t, err)
+		w2, err := fn(io.Discard)
+		require.NoError(t, err)
+		require.NotSame(t, w1, w2)
+		switch w1 := w1.(type) {
+		case *pooledGzipWriter:
+			require.NotSame(t, w1.Writer, w2.(*pooledGzipWriter).Writer)
+		case *pooledZlibWriter:
+			require.NotSame(t, w1.Writer, w2.(*pooledZlibWriter).Writer)
+		}
+		require.NoError(t, w1.Close())
+		require.NoError(t, w2.Close())
+	}
+}
+
+func TestCompressibleType(t *testing.T) {
+	require.True(t, compressibleType(ContentTypeApplicationJSONUTF8))
+	require.True(t, compressibleType("image/svg+xml"))
+	require.False(t, compressibleType("image/png"))
+	require.False(t, compressibleType("application/zip"))
+	require.False(t, compressibleType(""))
+}
+
+func TestCompression(t *testing.T) {
+	large := strings.Repeat("hello world ", 200)
+
+	a := Basic(WithCompressor("x-test", func(w io.Writer) (CompressWriter, error) {
+		return testCompressWriter{w}, nil
+	}))
+	a.HandleFunc("/large", func(c Context) {
+		c.Text(large)
+	})
+	a.HandleFunc("/small", func(c Context) {
+		c.Text("hello")
+	})
+	a.HandleFunc("/png", func(c Context) {
+		c.Body("image/png", []byte(large))
+	})
+
+	do := func(path string, accept string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", accept)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := do("/large", "gzip")
+	require.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+	require.Equal(t, strconv.Itoa(rw.Body.Len()), rw.Header().Get("Content-Length"))
+	require.Less(t, rw.Body.Len(), len(large))
+	gr, err := gzip.NewReader(rw.Body)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	require.Equal(t, large, string(buf))
+
+	rw = do("/large", "deflate")
+	require.Equal(t, "deflate", rw.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(rw.Body)
+	require.NoError(t, err)
+	buf, err = io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, large, string(buf))
+
+	// registered compressor preferred, but not compressing anything
+	rw = do("/large", "gzip, x-test")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, large, rw.Body.String())
+
+	rw = do("/large", "")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+
+	rw = do("/small", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "hello", rw.Body.String())
+
+	rw = do("/png", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Empty(t, rw.Header().Get("Vary"))
+
+	a = Basic(WithCompressionMinSize(-1))
+	a.HandleFunc("/large", func(c Context) {
+		c.Text(large)
+	})
+	rw = do("/large", "gzip")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+}
+
+func TestCompressionStream(t *testing.T) {
+	next := make(chan struct{})
+
+	a := Basic(WithStreamFlushInterval(0))
+	a.HandleFunc("/records", func(c Context) {
+		c.NDJSON(func(enc *NDJSONEncoder) error {
+			_ = enc.Encode(1)
+			<-next
+			_ = enc.Encode(2)
+			return nil
+		})
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	req, err := http.NewRequest("GET", s.URL+"/records", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	res, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+	require.Empty(t, res.Header.Get("Content-Length"))
+
+	gr, err := gzip.NewReader(res.Body)
+	require.NoError(t, err)
+	br := bufio.NewReader(gr)
+
+	// first record arrives before the second one is written
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "1\n", line)
+
+	close(next)
+
+	rest, err := io.ReadAll(br)
+	require.NoError(t, err)
+	require.Equal(t, "2\n", string(rest))
+}
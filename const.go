@@ -4,6 +4,19 @@ const (
 	ContentTypeApplicationJSON = "application/json"
 	ContentTypeTextPlain       = "text/plain"
 	ContentTypeFormURLEncoded  = "application/x-www-form-urlencoded"
+	ContentTypeMultipartForm   = "multipart/form-data"
+
+	ContentTypeApplicationXML           = "application/xml"
+	ContentTypeTextXML                  = "text/xml"
+	ContentTypeApplicationMsgpack       = "application/msgpack"
+	ContentTypeApplicationMsgpackLegacy = "application/x-msgpack"
+	ContentTypeApplicationCBOR          = "application/cbor"
+	ContentTypeApplicationProtobuf      = "application/x-protobuf"
+	ContentTypeTextEventStream          = "text/event-stream"
+	ContentTypeApplicationNDJSON        = "application/x-ndjson"
+	ContentTypeApplicationYAML          = "application/yaml"
+	ContentTypeTextHTML                 = "text/html"
+	ContentTypeApplicationProblemJSON   = "application/problem+json"
 
 	ContentTypeApplicationJSONUTF8 = "application/json; charset=utf-8"
 	ContentTypeTextPlainUTF8       = "text/plain; charset=utf-8"
@@ -12,4 +25,5 @@ const (
 	DefaultReadinessPath = "/debug/ready"
 	DefaultLivenessPath  = "/debug/alive"
 	DefaultMetricsPath   = "/debug/metrics"
+	DefaultOpenAPIPath   = "/debug/openapi.json"
 )
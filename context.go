@@ -3,14 +3,28 @@ package summer
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/guoyk93/rg"
+	"io"
+	"io/fs"
+	"mime/multipart"
 	"net/http"
+	"runtime/debug"
 	"strconv"
 	"sync"
 	"time"
 )
 
+type contextKey int
+
+const (
+	contextKeyPathParams contextKey = iota
+	contextKeyMountPrefix
+	contextKeyOptions
+	contextKeyConcurrencyRelease
+	contextKeyTraceID
+	contextKeyDrainer
+)
+
 // Bind a generic version of [Context.Bind]
 //
 // example:
@@ -49,9 +63,63 @@ type Context interface {
 	//
 	// HTTP query is prefixed with "query_"
 	//
-	// both JSON and Form are supported
+	// path parameters captured by pattern are prefixed with "path_"
+	//
+	// HTTP cookie is prefixed with "cookie_"
+	//
+	// A field can also be pinned to a single source with tag `bind:"header"`, "path", "query", "cookie" or "body",
+	// and have a default value with tag `default:"..."`. Precedence, from low to high, is:
+	//
+	//   - default value
+	//   - query, for fields not pinned
+	//   - body, for fields not pinned or pinned to body
+	//
+	// Fields pinned to header, path, query or cookie, including the prefixed ones, are never set by other sources.
+	//
+	// Strings from header, query and form are converted to the field type, including numbers, bools,
+	// [time.Duration] and [time.Time], invalid values are rejected with [http.StatusBadRequest]
+	//
+	// JSON, Form, Multipart Form, XML and msgpack are supported, more decoders can be registered by [WithDecoder],
+	// files in Multipart Form are available via [Context.File]
+	//
+	// Struct is validated with [Validate] after binding
 	Bind(data interface{})
 
+	// File returns the first file uploaded with given field name in a multipart/form-data request, or nil
+	//
+	// Temporary files are removed in [Context.Perform]
+	File(name string) *multipart.FileHeader
+
+	// Files returns all files uploaded in a multipart/form-data request
+	Files() map[string][]*multipart.FileHeader
+
+	// SendFile send a file from local filesystem, see [Context.SendFileFS]
+	SendFile(file string)
+
+	// SendFileFS send a file from fsys, with Content-Type by extension, ETag, byte ranges and conditional requests
+	// like If-None-Match and If-Modified-Since
+	//
+	// A precompressed sibling like "app.js.br" or "app.js.gz" is sent instead if accepted by client.
+	// A missing file is responded with [http.StatusNotFound]
+	SendFileFS(fsys fs.FS, name string)
+
+	// Attachment send content from r as a downloaded file with name, byte ranges are supported if r is an
+	// [io.ReadSeeker], r is not closed
+	Attachment(name string, r io.Reader)
+
+	// Cookie returns value of a request cookie, verified or decrypted with [CookieWithSigned] or [CookieWithEncrypted],
+	// ok is false if cookie is missing or invalid
+	Cookie(name string, opts ...CookieOption) (value string, ok bool)
+
+	// SetCookie set a response cookie, with Path "/", HttpOnly, SameSite Lax, and Secure if request is TLS
+	// or forwarded as https by default
+	//
+	// Value can be signed or encrypted with [CookieWithSigned] or [CookieWithEncrypted], see [WithCookieSecret]
+	SetCookie(name string, value string, opts ...CookieOption)
+
+	// ClearCookie expire a cookie in client, path and domain should match the ones used in [Context.SetCookie]
+	ClearCookie(name string, opts ...CookieOption)
+
 	// Code set the response code, can be called multiple times
 	Code(code int)
 
@@ -64,16 +132,60 @@ type Context interface {
 	// JSON set the response body to json
 	JSON(data interface{})
 
+	// Render set the response body to v, encoded by an encoder negotiated from Accept header with q-values,
+	// see [WithEncoder]. JSON is used if Accept header is missing.
+	//
+	// A request accepting none of encoders is rejected with [http.StatusNotAcceptable].
+	// Error responses in [Context.Perform] are negotiated the same way
+	Render(v any)
+
+	// Stream write the response progressively with fn, without buffering or Content-Length,
+	// headers are sent with the first byte written, and data is flushed periodically, see [WithStreamFlushInterval]
+	//
+	// If fn fails or panics before writing anything, an error response is sent as usual. Once started, an error
+	// returned by fn is reported with trailer [StreamErrorTrailer], or by terminating the connection if trailers
+	// are not supported by the protocol, and a panic always terminates the connection
+	Stream(contentType string, fn func(w io.Writer) error)
+
+	// NDJSON stream newline delimited JSON records with [Context.Stream]
+	NDJSON(fn func(enc *NDJSONEncoder) error)
+
+	// SSE start a Server-Sent Events stream, headers are sent immediately with the current response code,
+	// the buffered response body is discarded, and heartbeat comments are sent periodically, see [WithSSEHeartbeat]
+	//
+	// Use [Context.Done] to detect client disconnects and [App.Run] shutting down. A panic after stream started
+	// ends the stream silently, since no error response can be written anymore
+	SSE() SSEWriter
+
+	// Upgrade upgrade the request to a WebSocket connection, a request not being a valid handshake is rejected
+	// with [http.StatusBadRequest], a cross-origin request is rejected with [http.StatusForbidden],
+	// see [WebSocketWithCheckOrigin]
+	//
+	// Once upgraded, the concurrency slot of request is released, the buffered response is discarded,
+	// and the connection is closed in [Context.Perform], with [WebSocketCloseInternalError] if handler panicked.
+	// A close frame with [WebSocketCloseGoingAway] is sent when [App.Run] shuts down, and new handshakes are
+	// rejected with [http.StatusServiceUnavailable] from then on
+	Upgrade(opts ...WebSocketOption) *WebSocketConn
+
 	// Perform actually perform the response
 	// it is suggested to use in defer, recover() is included to recover from any panics
 	Perform()
 }
 
 type basicContext struct {
-	req *http.Request
-	rw  http.ResponseWriter
+	req  *http.Request
+	rw   http.ResponseWriter
+	opts *options
+
+	src  *bindSource
+	form *multipart.Form
 
-	buf []byte
+	sse      *sseWriter
+	ws       *WebSocketConn
+	streamed bool
+
+	// cancel context of long-lived handler, see [basicContext.watchDrain]
+	cancel context.CancelFunc
 
 	code int
 	body []byte
@@ -115,21 +227,45 @@ func (c *basicContext) Res() http.ResponseWriter {
 }
 
 func (c *basicContext) receive() {
-	var m = map[string]any{}
-	if err := extractRequest(m, c.req); err != nil {
+	var err error
+	if c.src, c.form, err = readRequest(c.req, c.opts); err != nil {
+		// keep status code from readRequest, like 413 and 415
+		if _, ok := err.(withStatusCode); ok {
+			raise(err)
+		}
 		Halt(err, HaltWithStatusCode(http.StatusBadRequest))
 	}
-	c.buf = rg.Must(json.Marshal(m))
 }
 
 func (c *basicContext) send() {
+	c.compressBody()
 	c.rw.WriteHeader(c.code)
 	_, _ = c.rw.Write(c.body)
 }
 
 func (c *basicContext) Bind(data interface{}) {
 	c.recvOnce.Do(c.receive)
-	rg.Must0(json.Unmarshal(c.buf, data))
+	if err := bindRequest(c.src, data); err != nil {
+		Halt(err, HaltWithBadRequest())
+	}
+	if err := Validate(data); err != nil {
+		raise(err)
+	}
+}
+
+func (c *basicContext) File(name string) *multipart.FileHeader {
+	if fhs := c.Files()[name]; len(fhs) > 0 {
+		return fhs[0]
+	}
+	return nil
+}
+
+func (c *basicContext) Files() map[string][]*multipart.FileHeader {
+	c.recvOnce.Do(c.receive)
+	if c.form == nil {
+		return nil
+	}
+	return c.form.File
 }
 
 func (c *basicContext) Code(code int) {
@@ -153,20 +289,98 @@ func (c *basicContext) JSON(data interface{}) {
 }
 
 func (c *basicContext) Perform() {
+	defer func() {
+		if c.form != nil {
+			_ = c.form.RemoveAll()
+		}
+		if c.cancel != nil {
+			c.cancel()
+		}
+	}()
+
+	var err error
 	if r := recover(); r != nil {
-		var (
-			e  error
-			ok bool
-		)
-		if e, ok = r.(error); !ok {
-			e = fmt.Errorf("panic: %v", r)
+		var recovered bool
+		err, recovered = errorFromRecovered(r)
+		if c.opts.productionMode {
+			c.ensureTraceID()
+		}
+		c.observe(err, recovered)
+	}
+
+	if c.ws != nil {
+		if err != nil {
+			_ = c.ws.Close(WebSocketCloseInternalError, "")
+		} else {
+			_ = c.ws.Close(WebSocketCloseNormal, "")
+		}
+		if d := drainerFromContext(c.req.Context()); d != nil {
+			d.untrack(c.ws)
+		}
+		return
+	}
+	if c.sse != nil {
+		// headers already sent, nothing but ending the stream
+		c.sse.close()
+		return
+	}
+	if c.streamed {
+		if err != nil {
+			// terminate the connection to avoid a truncated response looking complete
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+	if err != nil {
+		err = c.concealError(err)
+		if c.opts.errorRenderer != nil {
+			c.renderErrorCustom(err)
+		} else {
+			c.renderError(err)
 		}
-		c.Code(StatusCodeFromError(e))
-		c.JSON(BodyFromError(e))
 	}
 	c.sendOnce.Do(c.send)
 }
 
+// observe invoke error observers, a panicking observer is reported to the other observers as a [*PanicError]
+func (c *basicContext) observe(err error, recovered bool) {
+	for i, fn := range c.opts.errorObservers {
+		pe := c.invokeObserver(fn, err, recovered)
+		if pe == nil {
+			continue
+		}
+		for j, other := range c.opts.errorObservers {
+			if j != i {
+				_ = c.invokeObserver(other, pe, true)
+			}
+		}
+	}
+}
+
+// invokeObserver invoke an error observer, returns its panic as a [*PanicError]
+func (c *basicContext) invokeObserver(fn func(c Context, err error, recovered bool), err error, recovered bool) (pe *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			pe = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn(c, err, recovered)
+	return
+}
+
+// renderErrorCustom render error with [WithErrorRenderer], falls back to the default one if it panics,
+// the panic is reported to error observers as a [*PanicError]
+func (c *basicContext) renderErrorCustom(err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.observe(&PanicError{Value: r, Stack: debug.Stack()}, true)
+			c.renderError(err)
+		}
+	}()
+	c.Code(StatusCodeFromError(err))
+	c.opts.errorRenderer(c, err)
+}
+
 // ContextFactory factory function for creating an extended [Context]
 type ContextFactory[T Context] func(rw http.ResponseWriter, req *http.Request) T
 
@@ -175,6 +389,7 @@ func BasicContext(rw http.ResponseWriter, req *http.Request) Context {
 	return &basicContext{
 		req:      req,
 		rw:       rw,
+		opts:     optionsFromContext(req.Context()),
 		code:     http.StatusOK,
 		recvOnce: &sync.Once{},
 		sendOnce: &sync.Once{},
@@ -1,9 +1,14 @@
 package summer
 
 import (
+	"bytes"
+	"errors"
 	"github.com/stretchr/testify/require"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 )
 
@@ -74,9 +79,6 @@ func TestContextPanic(t *testing.T) {
 		require.Equal(t, "bbb", r.AAA)
 
 		panic("WWW")
-
-		ctx.Code(http.StatusTeapot)
-		ctx.Text("OK")
 	}()
 
 	rw.Flush()
@@ -85,3 +87,146 @@ func TestContextPanic(t *testing.T) {
 	require.Equal(t, "application/json; charset=utf-8", rw.Header().Get("Content-Type"))
 	require.Equal(t, `{"message":"panic: WWW"}`, rw.Body.String())
 }
+
+func TestContextFile(t *testing.T) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	require.NoError(t, mw.WriteField("name", "alice"))
+	fw, err := mw.CreateFormFile("avatar", "avatar.png")
+	require.NoError(t, err)
+	_, err = fw.Write(bytes.Repeat([]byte("a"), 1024))
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	var tmp *multipart.Form
+
+	a := Basic(WithMultipartMaxMemory(16))
+	a.HandleFunc("POST /upload", func(c Context) {
+		args := Bind[struct {
+			Name string `json:"name"`
+		}](c)
+
+		require.Nil(t, c.File("nothing"))
+
+		fh := c.File("avatar")
+		f, err := fh.Open()
+		require.NoError(t, err)
+		defer f.Close()
+		buf, err := io.ReadAll(f)
+		require.NoError(t, err)
+
+		tmp = c.(*basicContext).form
+
+		c.Text(args.Name + " " + fh.Filename + " " + strconv.Itoa(len(buf)) + " " + strconv.Itoa(len(c.Files())))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/upload", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	a.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "alice avatar.png 1024 1", rw.Body.String())
+
+	// temporary files removed
+	_, err = tmp.File["avatar"][0].Open()
+	require.Error(t, err)
+
+	a = Basic(WithMultipartMaxMemory(16), WithMultipartMaxDisk(16))
+	a.HandleFunc("POST /upload", func(c Context) {
+		c.Files()
+	})
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/upload", bytes.NewReader(body.Bytes()))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	a.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+}
+
+func TestContextErrorHooks(t *testing.T) {
+	type observed struct {
+		err       error
+		recovered bool
+	}
+	var records []observed
+
+	a := Basic(
+		WithErrorObserver(func(c Context, err error, recovered bool) {
+			if c.Req().URL.Path == "/observer-panic" && !recovered {
+				panic("observer panicked")
+			}
+		}),
+		WithErrorObserver(func(c Context, err error, recovered bool) {
+			records = append(records, observed{err: err, recovered: recovered})
+		}),
+		WithErrorRenderer(func(c Context, err error) {
+			if c.Req().URL.Path == "/renderer-panic" {
+				panic("renderer panicked")
+			}
+			c.Text("custom: " + err.Error())
+		}),
+	)
+	a.HandleFunc("/halt", func(c Context) {
+		HaltString("halted", HaltWithStatusCode(http.StatusConflict))
+	})
+	a.HandleFunc("/panic", func(c Context) {
+		var m map[string]int
+		m["crash"] = 1
+	})
+	a.HandleFunc("/renderer-panic", func(c Context) {
+		HaltString("halted", HaltWithBadRequest())
+	})
+	a.HandleFunc("/observer-panic", func(c Context) {
+		HaltString("halted", HaltWithBadRequest())
+	})
+	HandleTyped(a, "/typed", func(c Context, req struct{}) (any, error) {
+		return nil, errors.New("typed failed")
+	})
+
+	do := func(path string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := do("/halt")
+	require.Equal(t, http.StatusConflict, rw.Code)
+	require.Equal(t, "custom: halted", rw.Body.String())
+	require.Len(t, records, 1)
+	require.False(t, records[0].recovered)
+
+	rw = do("/panic")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "custom: assignment to entry in nil map", rw.Body.String())
+	require.Len(t, records, 2)
+	require.True(t, records[1].recovered)
+	var pe *PanicError
+	require.True(t, errors.As(records[1].err, &pe))
+	require.Contains(t, string(pe.Stack), "TestContextErrorHooks")
+
+	// renderer panic is reported after the error itself
+	rw = do("/renderer-panic")
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Equal(t, `{"message":"halted"}`, rw.Body.String())
+	require.Len(t, records, 4)
+	require.False(t, records[2].recovered)
+	require.True(t, records[3].recovered)
+	require.True(t, errors.As(records[3].err, &pe))
+	require.Equal(t, "renderer panicked", pe.Value)
+	require.Contains(t, string(pe.Stack), "TestContextErrorHooks")
+
+	// observer panic is reported to the other observers
+	rw = do("/observer-panic")
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Equal(t, "custom: halted", rw.Body.String())
+	require.Len(t, records, 6)
+	require.True(t, records[4].recovered)
+	require.True(t, errors.As(records[4].err, &pe))
+	require.Equal(t, "observer panicked", pe.Value)
+	require.False(t, records[5].recovered)
+
+	rw = do("/typed")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "custom: typed failed", rw.Body.String())
+	require.False(t, records[6].recovered)
+}
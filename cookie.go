@@ -0,0 +1,249 @@
+package summer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type cookieMode int
+
+const (
+	cookieModePlain cookieMode = iota
+	cookieModeSigned
+	cookieModeEncrypted
+)
+
+type cookieOptions struct {
+	cookie http.Cookie
+	mode   cookieMode
+}
+
+// CookieOption configuration function for [Context.SetCookie], [Context.ClearCookie] and [Context.Cookie]
+type CookieOption func(o *cookieOptions)
+
+// CookieWithPath a [CookieOption] setting path, default to "/"
+func CookieWithPath(path string) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.Path = path
+	}
+}
+
+// CookieWithDomain a [CookieOption] setting domain
+func CookieWithDomain(domain string) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.Domain = domain
+	}
+}
+
+// CookieWithMaxAge a [CookieOption] setting max age, default to a session cookie
+func CookieWithMaxAge(d time.Duration) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.MaxAge = int(d / time.Second)
+		o.cookie.Expires = time.Now().Add(d)
+	}
+}
+
+// CookieWithSameSite a [CookieOption] setting SameSite, default to [http.SameSiteLaxMode]
+func CookieWithSameSite(s http.SameSite) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.SameSite = s
+	}
+}
+
+// CookieWithHTTPOnly a [CookieOption] setting HttpOnly, default to true
+func CookieWithHTTPOnly(b bool) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.HttpOnly = b
+	}
+}
+
+// CookieWithSecure a [CookieOption] setting Secure, default to true if request is TLS or forwarded as https
+func CookieWithSecure(b bool) CookieOption {
+	return func(o *cookieOptions) {
+		o.cookie.Secure = b
+	}
+}
+
+// CookieWithSigned a [CookieOption] signing the value with HMAC-SHA256, requires [WithCookieSecret]
+func CookieWithSigned() CookieOption {
+	return func(o *cookieOptions) {
+		o.mode = cookieModeSigned
+	}
+}
+
+// CookieWithEncrypted a [CookieOption] encrypting the value with AES-GCM, requires [WithCookieSecret]
+func CookieWithEncrypted() CookieOption {
+	return func(o *cookieOptions) {
+		o.mode = cookieModeEncrypted
+	}
+}
+
+// isSecureRequest check if request is TLS, or forwarded as https by a proxy
+func isSecureRequest(req *http.Request) bool {
+	if req.TLS != nil {
+		return true
+	}
+	proto, _, _ := strings.Cut(req.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// newCookieOptions create cookie options with secure defaults
+func newCookieOptions(req *http.Request, name string, opts []CookieOption) *cookieOptions {
+	o := &cookieOptions{
+		cookie: http.Cookie{
+			Name:     name,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			Secure:   isSecureRequest(req),
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+var (
+	errCookieSecretMissing = errors.New("cookie secret is not configured")
+	errCookieInvalid       = errors.New("invalid cookie value")
+)
+
+// deriveCookieKey derive a key for purpose from secret
+func deriveCookieKey(secret []byte, purpose string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte("summer-cookie-" + purpose))
+	return h.Sum(nil)
+}
+
+func cookieSignature(secret []byte, name string, payload string) []byte {
+	h := hmac.New(sha256.New, deriveCookieKey(secret, "sign"))
+	h.Write([]byte(name))
+	h.Write([]byte{'='})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// encodeCookieValue sign or encrypt a cookie value, the first secret is used
+func encodeCookieValue(secrets [][]byte, mode cookieMode, name string, value string) (string, error) {
+	if mode == cookieModePlain {
+		return value, nil
+	}
+	if len(secrets) == 0 {
+		return "", errCookieSecretMissing
+	}
+
+	if mode == cookieModeSigned {
+		payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+		return payload + "." + base64.RawURLEncoding.EncodeToString(cookieSignature(secrets[0], name, payload)), nil
+	}
+
+	block, err := aes.NewCipher(deriveCookieKey(secrets[0], "encrypt"))
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+// decodeCookieValue verify or decrypt a cookie value, all secrets are tried for key rotation
+func decodeCookieValue(secrets [][]byte, mode cookieMode, name string, value string) (string, error) {
+	if mode == cookieModePlain {
+		return value, nil
+	}
+	if len(secrets) == 0 {
+		return "", errCookieSecretMissing
+	}
+
+	if mode == cookieModeSigned {
+		payload, sig, ok := strings.Cut(value, ".")
+		if !ok {
+			return "", errCookieInvalid
+		}
+		sigBuf, err := base64.RawURLEncoding.DecodeString(sig)
+		if err != nil {
+			return "", errCookieInvalid
+		}
+		for _, secret := range secrets {
+			if hmac.Equal(sigBuf, cookieSignature(secret, name, payload)) {
+				buf, err := base64.RawURLEncoding.DecodeString(payload)
+				if err != nil {
+					return "", errCookieInvalid
+				}
+				return string(buf), nil
+			}
+		}
+		return "", errCookieInvalid
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", errCookieInvalid
+	}
+	for _, secret := range secrets {
+		block, err := aes.NewCipher(deriveCookieKey(secret, "encrypt"))
+		if err != nil {
+			return "", err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return "", err
+		}
+		if len(buf) < aead.NonceSize() {
+			return "", errCookieInvalid
+		}
+		if plain, err := aead.Open(nil, buf[:aead.NonceSize()], buf[aead.NonceSize():], []byte(name)); err == nil {
+			return string(plain), nil
+		}
+	}
+	return "", errCookieInvalid
+}
+
+func (c *basicContext) SetCookie(name string, value string, opts ...CookieOption) {
+	o := newCookieOptions(c.req, name, opts)
+	var err error
+	if o.cookie.Value, err = encodeCookieValue(c.opts.cookieSecrets, o.mode, name, value); err != nil {
+		raise(err)
+	}
+	http.SetCookie(c.rw, &o.cookie)
+}
+
+func (c *basicContext) ClearCookie(name string, opts ...CookieOption) {
+	o := newCookieOptions(c.req, name, opts)
+	o.cookie.Value = ""
+	o.cookie.MaxAge = -1
+	o.cookie.Expires = time.Unix(0, 0)
+	http.SetCookie(c.rw, &o.cookie)
+}
+
+func (c *basicContext) Cookie(name string, opts ...CookieOption) (value string, ok bool) {
+	o := newCookieOptions(c.req, name, opts)
+	ck, err := c.req.Cookie(name)
+	if err != nil {
+		return
+	}
+	if value, err = decodeCookieValue(c.opts.cookieSecrets, o.mode, name, ck.Value); err != nil {
+		if err == errCookieSecretMissing {
+			raise(err)
+		}
+		value = ""
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,94 @@
+package summer
+
+import (
+	"crypto/tls"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCookieValue(t *testing.T) {
+	secrets := [][]byte{[]byte("new"), []byte("old")}
+
+	for _, mode := range []cookieMode{cookieModeSigned, cookieModeEncrypted} {
+		v, err := encodeCookieValue(secrets[1:], mode, "session", "hello")
+		require.NoError(t, err)
+		require.NotContains(t, v, "hello")
+
+		// rotated
+		s, err := decodeCookieValue(secrets, mode, "session", v)
+		require.NoError(t, err)
+		require.Equal(t, "hello", s)
+
+		// bound to name
+		_, err = decodeCookieValue(secrets, mode, "other", v)
+		require.Error(t, err)
+
+		// tampered
+		_, err = decodeCookieValue(secrets, mode, "session", "A"+v)
+		require.Error(t, err)
+
+		_, err = encodeCookieValue(nil, mode, "session", "hello")
+		require.Equal(t, errCookieSecretMissing, err)
+	}
+}
+
+func TestContextCookie(t *testing.T) {
+	a := Basic(WithCookieSecret([]byte("secret")))
+	a.HandleFunc("/set", func(c Context) {
+		c.SetCookie("plain", "p")
+		c.SetCookie("signed", "s", CookieWithSigned(), CookieWithMaxAge(time.Hour))
+		c.SetCookie("encrypted", "e", CookieWithEncrypted(), CookieWithPath("/api"), CookieWithSameSite(http.SameSiteStrictMode))
+		c.ClearCookie("old")
+	})
+	a.HandleFunc("/get", func(c Context) {
+		args := Bind[struct {
+			Plain string `json:"cookie_plain"`
+		}](c)
+		signed, ok1 := c.Cookie("signed", CookieWithSigned())
+		encrypted, ok2 := c.Cookie("encrypted", CookieWithEncrypted())
+		_, ok3 := c.Cookie("plain", CookieWithSigned())
+		require.True(t, ok1)
+		require.True(t, ok2)
+		require.False(t, ok3)
+		c.Text(args.Plain + signed + encrypted)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/set", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	a.ServeHTTP(rw, req)
+
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 4)
+	for _, ck := range cookies {
+		require.True(t, ck.HttpOnly)
+		require.True(t, ck.Secure)
+	}
+	require.Equal(t, "/", cookies[0].Path)
+	require.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	require.Equal(t, 3600, cookies[1].MaxAge)
+	require.Equal(t, "/api", cookies[2].Path)
+	require.Equal(t, http.SameSiteStrictMode, cookies[2].SameSite)
+	require.Equal(t, "old", cookies[3].Name)
+	require.Equal(t, -1, cookies[3].MaxAge)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/get", nil)
+	for _, ck := range cookies[:3] {
+		req.AddCookie(&http.Cookie{Name: ck.Name, Value: ck.Value})
+	}
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "pse", rw.Body.String())
+}
+
+func TestIsSecureRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	require.False(t, isSecureRequest(req))
+	req.Header.Set("X-Forwarded-Proto", "https, http")
+	require.True(t, isSecureRequest(req))
+	req = httptest.NewRequest("GET", "http://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	require.True(t, isSecureRequest(req))
+}
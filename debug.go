@@ -0,0 +1,169 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	HaltExtraKeyOrigin = "origin"
+	HaltExtraKeyStack  = "stack"
+)
+
+// haltSourceDir directory of source files of this package, skipped when looking for origin of a [HaltError]
+var haltSourceDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// haltCallersDepth number of program counters captured by [NewHaltError], enough to leave this package
+const haltCallersDepth = 8
+
+// haltCallers program counters where a [HaltError] was created, captured for every app since it is cheap,
+// frames are only resolved when rendering in dev mode
+type haltCallers struct {
+	pcs [haltCallersDepth]uintptr
+	n   int
+}
+
+// capture program counters of the current goroutine, skipping frames of callers and itself
+func (hc *haltCallers) capture(skip int) {
+	hc.n = runtime.Callers(skip+2, hc.pcs[:])
+}
+
+// origin returns "file:line" of the first frame outside this package, like the handler calling [Halt],
+// test files of this package are not skipped
+func (h *haltError) origin() string {
+	frames := runtime.CallersFrames(h.callers.pcs[:h.callers.n])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && (filepath.Dir(frame.File) != haltSourceDir || strings.HasSuffix(frame.File, "_test.go")) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// haltOriginOf returns origin of the outermost [HaltError] created explicitly, in the order of
+// [StatusCodeFromError] precedence
+func haltOriginOf(err error) string {
+	if he, ok := err.(*haltError); ok && !he.raised {
+		if s := he.origin(); s != "" {
+			return s
+		}
+	}
+	for _, e := range unwrapError(err) {
+		if s := haltOriginOf(e); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// devMode check if details of errors should be exposed, production mode always wins
+func (c *basicContext) devMode() bool {
+	return c.opts.devMode && !c.opts.productionMode
+}
+
+// addDebugExtras add origin of [Halt] and stack trace of [PanicError] to an error body in dev mode
+func addDebugExtras(m map[string]any, err error) {
+	if s := haltOriginOf(err); s != "" {
+		m[HaltExtraKeyOrigin] = s
+	}
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		m[HaltExtraKeyStack] = string(pe.Stack)
+	}
+}
+
+// prefersHTML check if client prefers HTML over JSON, like a browser
+func prefersHTML(header string) bool {
+	if strings.TrimSpace(header) == "" {
+		return false
+	}
+	ranges := parseAccept(header)
+	return acceptQ(ranges, ContentTypeTextHTML) > acceptQ(ranges, ContentTypeApplicationJSON)
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.StatusText}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { color: #b00020; }
+pre { background: #f5f5f5; padding: 1em; overflow: auto; }
+th { text-align: left; padding-right: 1em; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+<table>
+<tr><th>Request</th><td>{{.Method}} {{.URL}}</td></tr>
+{{- if .Origin}}
+<tr><th>Origin</th><td><code>{{.Origin}}</code></td></tr>
+{{- end}}
+</table>
+{{- if .Body}}
+<h2>Body</h2>
+<pre>{{.Body}}</pre>
+{{- end}}
+{{- if .Stack}}
+<h2>Stack</h2>
+<pre>{{.Stack}}</pre>
+{{- end}}
+<h2>Request Headers</h2>
+<pre>{{.Headers}}</pre>
+</body>
+</html>
+`))
+
+// renderDebugPage render an HTML page with details of error body m, for dev mode
+func (c *basicContext) renderDebugPage(m map[string]any) {
+	data := map[string]any{
+		"Status":     c.code,
+		"StatusText": http.StatusText(c.code),
+		"Method":     c.req.Method,
+		"URL":        c.req.URL.String(),
+	}
+
+	body := map[string]any{}
+	for k, v := range m {
+		switch k {
+		case HaltExtraKeyMessage, ProblemKeyDetail:
+			data["Message"] = v
+		case HaltExtraKeyOrigin:
+			data["Origin"] = v
+		case HaltExtraKeyStack:
+			data["Stack"] = v
+		default:
+			body[k] = v
+		}
+	}
+	if len(body) > 0 {
+		buf, _ := json.MarshalIndent(body, "", "  ")
+		data["Body"] = string(buf)
+	}
+
+	headers := &bytes.Buffer{}
+	_ = c.req.Header.Write(headers)
+	data["Headers"] = headers.String()
+
+	buf := &bytes.Buffer{}
+	if err := debugPageTemplate.Execute(buf, data); err != nil {
+		buf.Reset()
+		buf.WriteString(template.HTMLEscapeString(err.Error()))
+	}
+	c.Body(ContentTypeTextHTML+"; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,99 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHaltOrigin(t *testing.T) {
+	err := NewHaltError(errors.New("test"))
+	require.True(t, strings.HasSuffix(haltOriginOf(err), "debug_test.go:14"), haltOriginOf(err))
+
+	var recovered error
+	func() {
+		defer func() {
+			recovered = recover().(error)
+		}()
+		HaltString("test", HaltWithBadRequest())
+	}()
+	require.True(t, strings.HasSuffix(haltOriginOf(recovered), "debug_test.go:22"), haltOriginOf(recovered))
+
+	require.Equal(t, "", haltOriginOf(errors.New("plain")))
+}
+
+func TestPrefersHTML(t *testing.T) {
+	require.True(t, prefersHTML("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"))
+	require.False(t, prefersHTML("application/json, text/html"))
+	require.False(t, prefersHTML("*/*"))
+	require.False(t, prefersHTML(""))
+}
+
+func TestDevMode(t *testing.T) {
+	newApp := func(opts ...Option) App[Context] {
+		a := Basic(opts...)
+		a.HandleFunc("/halt", func(c Context) {
+			HaltString("halted", HaltWithStatusCode(http.StatusConflict), HaltWithExtra("id", "1"))
+		})
+		a.HandleFunc("/panic", func(c Context) {
+			var m map[string]int
+			m["crash"] = 1
+		})
+		return a
+	}
+
+	do := func(a App[Context], path string, accept string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	a := newApp(WithDevMode(true))
+
+	rw := do(a, "/halt", "")
+	require.Equal(t, http.StatusConflict, rw.Code)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "halted", m["message"])
+	require.Contains(t, m["origin"], "debug_test.go:")
+	require.NotContains(t, m, "stack")
+
+	rw = do(a, "/panic", "")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	m = nil
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "assignment to entry in nil map", m["message"])
+	require.Contains(t, m["stack"], "TestDevMode")
+
+	rw = do(a, "/panic", "text/html,*/*;q=0.8")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	require.Contains(t, rw.Body.String(), "<h1>500 Internal Server Error</h1>")
+	require.Contains(t, rw.Body.String(), "assignment to entry in nil map")
+	require.Contains(t, rw.Body.String(), "TestDevMode")
+
+	rw = do(a, "/halt", "text/html")
+	require.Contains(t, rw.Body.String(), "<h1>409 Conflict</h1>")
+	require.Contains(t, rw.Body.String(), "debug_test.go:")
+	require.Contains(t, rw.Body.String(), `&#34;id&#34;: &#34;1&#34;`)
+
+	// production mode never leaks details
+	a = newApp(WithDevMode(true), WithProductionMode(true))
+
+	rw = do(a, "/panic", "text/html")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.NotContains(t, rw.Body.String(), "TestDevMode")
+	require.NotContains(t, rw.Body.String(), "nil map")
+
+	rw = do(a, "/halt", "")
+	m = nil
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, map[string]any{"message": "halted", "id": "1"}, m)
+}
@@ -0,0 +1,180 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// maxDecodeDepth maximum nesting depth of XML and msgpack bodies, the same as [encoding/json], deeper input is
+// rejected instead of overflowing the stack
+const maxDecodeDepth = 10000
+
+// DecoderFunc decode a request body and merge values into m, for [Context.Bind]
+type DecoderFunc func(buf []byte, m map[string]any) (err error)
+
+// DecodeText decoder for text/plain, body is stored with key "text"
+func DecodeText(buf []byte, m map[string]any) (err error) {
+	m["text"] = string(buf)
+	return
+}
+
+// DecodeJSON decoder for application/json
+func DecodeJSON(buf []byte, m map[string]any) (err error) {
+	var j map[string]any
+	if err = json.Unmarshal(buf, &j); err != nil {
+		return
+	}
+	for k, v := range j {
+		m[k] = v
+	}
+	return
+}
+
+// DecodeForm decoder for application/x-www-form-urlencoded
+func DecodeForm(buf []byte, m map[string]any) (err error) {
+	var q url.Values
+	if q, err = url.ParseQuery(string(buf)); err != nil {
+		return
+	}
+	for k, vs := range q {
+		m[k] = flattenSingleSlice(vs)
+	}
+	return
+}
+
+// DecodeXML decoder for application/xml
+//
+// Children and attributes of the root element are stored with their local names, repeated children become a slice,
+// and elements without children or attributes become strings
+func DecodeXML(buf []byte, m map[string]any) (err error) {
+	d := xml.NewDecoder(bytes.NewReader(buf))
+	for {
+		var tok xml.Token
+		if tok, err = d.Token(); err != nil {
+			if err == io.EOF {
+				err = errors.New("xml: missing root element")
+			}
+			return
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			var v any
+			if v, err = decodeXMLElement(d, start, 1); err != nil {
+				return
+			}
+			if vm, ok := v.(map[string]any); ok {
+				for k, v := range vm {
+					m[k] = v
+				}
+			}
+			return
+		}
+	}
+}
+
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement, depth int) (v any, err error) {
+	if depth > maxDecodeDepth {
+		err = errors.New("xml: exceeded max depth")
+		return
+	}
+
+	var (
+		children map[string]any
+		text     strings.Builder
+	)
+
+	set := func(k string, v any) {
+		if children == nil {
+			children = map[string]any{}
+		}
+		if prev, ok := children[k]; ok {
+			if s, ok := prev.([]any); ok {
+				children[k] = append(s, v)
+			} else {
+				children[k] = []any{prev, v}
+			}
+		} else {
+			children[k] = v
+		}
+	}
+
+	for _, attr := range start.Attr {
+		set(attr.Name.Local, attr.Value)
+	}
+
+	for {
+		var tok xml.Token
+		if tok, err = d.Token(); err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var cv any
+			if cv, err = decodeXMLElement(d, t, depth+1); err != nil {
+				return
+			}
+			set(t.Name.Local, cv)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if children == nil {
+				v = text.String()
+			} else {
+				v = children
+			}
+			return
+		}
+	}
+}
+
+// DecodeMsgpack decoder for application/msgpack, the root object must be a map
+func DecodeMsgpack(buf []byte, m map[string]any) (err error) {
+	d := &msgpackDecoder{buf: buf}
+	var v any
+	if v, err = d.decode(); err != nil {
+		return
+	}
+	vm, ok := v.(map[string]any)
+	if !ok {
+		err = errors.New("msgpack: root object is not a map")
+		return
+	}
+	for k, v := range vm {
+		m[k] = v
+	}
+	return
+}
+
+// decoder a registered [DecoderFunc], rawJSON is true for the built-in JSON decoder, whose body is decoded directly
+// into the target by [Context.Bind], skipping the intermediate map
+type decoder struct {
+	fn      DecoderFunc
+	rawJSON bool
+}
+
+func defaultDecoders() map[string]decoder {
+	return map[string]decoder{
+		ContentTypeTextPlain:                {fn: DecodeText},
+		ContentTypeApplicationJSON:          {fn: DecodeJSON, rawJSON: true},
+		ContentTypeFormURLEncoded:           {fn: DecodeForm},
+		ContentTypeApplicationXML:           {fn: DecodeXML},
+		ContentTypeTextXML:                  {fn: DecodeXML},
+		ContentTypeApplicationMsgpack:       {fn: DecodeMsgpack},
+		ContentTypeApplicationMsgpackLegacy: {fn: DecodeMsgpack},
+	}
+}
+
+// lookupDecoder find decoder for a media type, structured syntax suffix like "+json" falls back to "application/json"
+func lookupDecoder(decoders map[string]decoder, mediaType string) (d decoder, ok bool) {
+	if d, ok = decoders[mediaType]; ok {
+		return
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		d, ok = decoders["application/"+mediaType[i+1:]]
+	}
+	return
+}
@@ -0,0 +1,150 @@
+package summer
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeXML(t *testing.T) {
+	m := map[string]any{}
+	err := DecodeXML([]byte(`<?xml version="1.0"?><user id="1"><name>alice</name><tag>a</tag><tag>b</tag><address><city>x</city></address></user>`), m)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"id":      "1",
+		"name":    "alice",
+		"tag":     []any{"a", "b"},
+		"address": map[string]any{"city": "x"},
+	}, m)
+
+	err = DecodeXML([]byte(``), map[string]any{})
+	require.Error(t, err)
+}
+
+func TestDecodeDeeplyNested(t *testing.T) {
+	// nested arrays in a map, one level deeper than allowed
+	buf := append([]byte{0x81, 0xa1, 'a'}, bytes.Repeat([]byte{0x91}, maxDecodeDepth)...)
+	buf = append(buf, 0x90)
+	require.EqualError(t, DecodeMsgpack(buf, map[string]any{}), "msgpack: exceeded max depth")
+
+	// a huge body must fail with an error instead of overflowing the stack
+	buf = append([]byte{0x81, 0xa1, 'a'}, bytes.Repeat([]byte{0x91}, 1<<20)...)
+	require.Error(t, DecodeMsgpack(buf, map[string]any{}))
+
+	// nesting within the limit is fine
+	buf = append([]byte{0x81, 0xa1, 'a'}, bytes.Repeat([]byte{0x91}, maxDecodeDepth-2)...)
+	buf = append(buf, 0x90)
+	require.NoError(t, DecodeMsgpack(buf, map[string]any{}))
+
+	s := strings.Repeat("<a>", maxDecodeDepth+1) + strings.Repeat("</a>", maxDecodeDepth+1)
+	require.EqualError(t, DecodeXML([]byte(s), map[string]any{}), "xml: exceeded max depth")
+
+	s = strings.Repeat("<a>", 1<<20)
+	require.Error(t, DecodeXML([]byte(s), map[string]any{}))
+}
+
+func TestMaxBodySize(t *testing.T) {
+	a := Basic(WithMaxBodySize(16))
+	a.HandleFunc("POST /test", func(c Context) {
+		c.Text(Bind[struct {
+			Name string `json:"name"`
+		}](c).Name)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test", strings.NewReader(`{"name":"alice"}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "alice", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test", strings.NewReader(`{"name":"alice1"}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rw.Code)
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	m := map[string]any{}
+	err := DecodeMsgpack([]byte{
+		0x86,
+		0xa4, 'n', 'a', 'm', 'e', 0xa5, 'a', 'l', 'i', 'c', 'e',
+		0xa3, 'a', 'g', 'e', 0x12,
+		0xa3, 'n', 'e', 'g', 0xff,
+		0xa2, 'o', 'k', 0xc3,
+		0xa4, 't', 'a', 'g', 's', 0x92, 0xa1, 'a', 0xcd, 0x01, 0x00,
+		0xa2, 't', 's', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x01,
+	}, m)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"name": "alice",
+		"age":  int64(18),
+		"neg":  int64(-1),
+		"ok":   true,
+		"tags": []any{"a", uint64(256)},
+		"ts":   time.Unix(1, 0).UTC(),
+	}, m)
+
+	require.Error(t, DecodeMsgpack([]byte{0x92, 0x01, 0x02}, map[string]any{}))
+	require.Error(t, DecodeMsgpack([]byte{0xdf, 0xff, 0xff, 0xff, 0xff}, map[string]any{}))
+	require.Error(t, DecodeMsgpack([]byte{0x81, 0xa1}, map[string]any{}))
+}
+
+func TestLookupDecoder(t *testing.T) {
+	decoders := defaultDecoders()
+	d, ok := lookupDecoder(decoders, "application/vnd.api+json")
+	require.True(t, ok)
+	require.True(t, d.rawJSON)
+	d, ok = lookupDecoder(decoders, "application/atom+xml")
+	require.True(t, ok)
+	require.False(t, d.rawJSON)
+	_, ok = lookupDecoder(decoders, ContentTypeApplicationCBOR)
+	require.False(t, ok)
+}
+
+func TestWithDecoder(t *testing.T) {
+	a := Basic(WithDecoder(ContentTypeApplicationCBOR, func(buf []byte, m map[string]any) (err error) {
+		m["cbor"] = string(buf)
+		return
+	}))
+	a.HandleFunc("POST /test", func(c Context) {
+		c.Text(Bind[struct {
+			CBOR string `json:"cbor"`
+		}](c).CBOR)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test", bytes.NewReader([]byte("hello")))
+	req.Header.Set("Content-Type", ContentTypeApplicationCBOR)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "hello", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test", bytes.NewReader([]byte("hello")))
+	req.Header.Set("Content-Type", ContentTypeApplicationProtobuf)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, rw.Code)
+	require.Equal(t, `{"message":"unsupported request body type: application/x-protobuf"}`, rw.Body.String())
+}
+
+func TestWithDecoderWrappingJSON(t *testing.T) {
+	var called bool
+	a := Basic(WithDecoder(ContentTypeApplicationJSON, func(buf []byte, m map[string]any) (err error) {
+		called = true
+		return DecodeJSON(buf, m)
+	}))
+	a.HandleFunc("POST /test", func(c Context) {
+		c.Text(Bind[struct {
+			Name string `json:"name"`
+		}](c).Name)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("POST", "https://example.com/test", bytes.NewReader([]byte(`{"name":"alice"}`)))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "alice", rw.Body.String())
+	require.True(t, called)
+}
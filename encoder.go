@@ -0,0 +1,147 @@
+package summer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"sort"
+	"strings"
+)
+
+// EncoderFunc encode a response value, for [Context.Render]
+type EncoderFunc func(v any) (buf []byte, err error)
+
+// genericValue convert v to values like [encoding/json] does with any, json tags are respected,
+// and numbers are kept as [json.Number]
+func genericValue(v any) (out any, err error) {
+	var buf []byte
+	if buf, err = json.Marshal(v); err != nil {
+		return
+	}
+	d := json.NewDecoder(bytes.NewReader(buf))
+	d.UseNumber()
+	err = d.Decode(&out)
+	return
+}
+
+// EncodeJSON encoder for application/json
+func EncodeJSON(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// EncodeXML encoder for application/xml
+//
+// Value is converted with json tags, encoded as children of root element "response", map keys become element names,
+// and slice items become repeated elements, the same shape as [DecodeXML] accepts
+func EncodeXML(v any) (buf []byte, err error) {
+	var g any
+	if g, err = genericValue(v); err != nil {
+		return
+	}
+	b := &bytes.Buffer{}
+	b.WriteString(xml.Header)
+	encodeXMLElement(b, "response", g)
+	buf = b.Bytes()
+	return
+}
+
+// xmlName sanitize a map key to a valid XML element name
+func xmlName(s string) string {
+	var sb strings.Builder
+	for i, c := range s {
+		switch {
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c > 0x7f:
+			sb.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(c)
+		case i > 0 && (c == '-' || c == '.'):
+			sb.WriteRune(c)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+func encodeXMLElement(b *bytes.Buffer, name string, v any) {
+	switch v := v.(type) {
+	case []any:
+		if name == "response" {
+			// root can not be repeated
+			b.WriteString("<response>")
+			for _, item := range v {
+				encodeXMLElement(b, "item", item)
+			}
+			b.WriteString("</response>")
+			return
+		}
+		for _, item := range v {
+			if _, ok := item.([]any); ok {
+				b.WriteString("<" + name + ">")
+				encodeXMLElement(b, "item", item)
+				b.WriteString("</" + name + ">")
+				continue
+			}
+			encodeXMLElement(b, name, item)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("<" + name + ">")
+		for _, k := range keys {
+			encodeXMLElement(b, xmlName(k), v[k])
+		}
+		b.WriteString("</" + name + ">")
+	case nil:
+		b.WriteString("<" + name + "/>")
+	default:
+		b.WriteString("<" + name + ">")
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case json.Number:
+			s = v.String()
+		case bool:
+			if v {
+				s = "true"
+			} else {
+				s = "false"
+			}
+		}
+		_ = xml.EscapeText(b, []byte(s))
+		b.WriteString("</" + name + ">")
+	}
+}
+
+// EncodeMsgpack encoder for application/msgpack, value is converted with json tags
+func EncodeMsgpack(v any) (buf []byte, err error) {
+	var g any
+	if g, err = genericValue(v); err != nil {
+		return
+	}
+	buf = appendMsgpack(nil, g)
+	return
+}
+
+// EncodeHTML encoder for text/html, value is shown as indented JSON, for browsers visiting an API
+//
+// Register another encoder with [WithEncoder] to render templates
+func EncodeHTML(v any) (buf []byte, err error) {
+	if buf, err = json.MarshalIndent(v, "", "  "); err != nil {
+		return
+	}
+	buf = []byte("<!doctype html>\n<meta charset=\"utf-8\">\n<pre>" + html.EscapeString(string(buf)) + "</pre>\n")
+	return
+}
@@ -0,0 +1,85 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testEncoderValue struct {
+	Name  string            `json:"name"`
+	Age   int               `json:"age"`
+	Tags  []string          `json:"tags"`
+	Extra map[string]any    `json:"extra,omitempty"`
+	Items []map[string]bool `json:"items"`
+}
+
+func TestEncodeXML(t *testing.T) {
+	buf, err := EncodeXML(testEncoderValue{
+		Name:  "a<b",
+		Age:   18,
+		Tags:  []string{"x", "y"},
+		Extra: map[string]any{"1st": nil},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><age>18</age><extra><_1st/></extra><items/><name>a&lt;b</name><tags>x</tags><tags>y</tags></response>`,
+		string(buf))
+
+	m := map[string]any{}
+	require.NoError(t, DecodeXML(buf, m))
+	require.Equal(t, "a<b", m["name"])
+	require.Equal(t, []any{"x", "y"}, m["tags"])
+}
+
+func TestEncodeMsgpack(t *testing.T) {
+	buf, err := EncodeMsgpack(map[string]any{
+		"int":    -300,
+		"big":    uint64(1 << 63),
+		"float":  1.5,
+		"bool":   true,
+		"nil":    nil,
+		"list":   []int{1, 2},
+		"nested": map[string]string{"a": "b"},
+	})
+	require.NoError(t, err)
+
+	m := map[string]any{}
+	require.NoError(t, DecodeMsgpack(buf, m))
+	require.Equal(t, map[string]any{
+		"int":    int64(-300),
+		"big":    uint64(1 << 63),
+		"float":  1.5,
+		"bool":   true,
+		"nil":    nil,
+		"list":   []any{int64(1), int64(2)},
+		"nested": map[string]any{"a": "b"},
+	}, m)
+}
+
+func TestEncodeYAML(t *testing.T) {
+	buf, err := EncodeYAML(testEncoderValue{
+		Name:  "yes",
+		Age:   18,
+		Tags:  []string{"x", "hello world"},
+		Items: []map[string]bool{{"a": true, "b": false}, {}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `age: 18
+items:
+  - a: true
+    b: false
+  - {}
+name: "yes"
+tags:
+  - x
+  - "hello world"
+`, string(buf))
+
+	buf, err = EncodeYAML([]any{})
+	require.NoError(t, err)
+	require.Equal(t, "[]\n", string(buf))
+
+	buf, err = EncodeYAML("a\nb")
+	require.NoError(t, err)
+	require.Equal(t, "\"a\\nb\"\n", string(buf))
+}
@@ -2,11 +2,16 @@ package summer
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"sync"
 )
 
 const (
 	HaltExtraKeyMessage = "message"
+	HaltExtraKeyTraceID = "trace_id"
 )
 
 // HaltOption configuration function for [HaltError]
@@ -51,6 +56,20 @@ func HaltWithExtras(m map[string]any) HaltOption {
 	}
 }
 
+// HaltWithType a [HaltOption] setting the "type" URI of problem details, see [WithProblemDetails]
+func HaltWithType(uri string) HaltOption {
+	return func(h *haltError) {
+		h.problemType = uri
+	}
+}
+
+// HaltWithTitle a [HaltOption] setting the "title" of problem details, see [WithProblemDetails]
+func HaltWithTitle(title string) HaltOption {
+	return func(h *haltError) {
+		h.problemTitle = title
+	}
+}
+
 type withStatusCode interface {
 	StatusCode() int
 }
@@ -63,16 +82,39 @@ type withUnwrap interface {
 	Unwrap() error
 }
 
+type withUnwrapMulti interface {
+	Unwrap() []error
+}
+
+type withProblem interface {
+	ProblemType() string
+	ProblemTitle() string
+}
+
 var (
 	_ withStatusCode = &haltError{}
 	_ withExtract    = &haltError{}
 	_ withUnwrap     = &haltError{}
+	_ withProblem    = &haltError{}
 )
 
 type haltError struct {
 	error
 	statusCode int
 	extras     map[string]any
+
+	problemType  string
+	problemTitle string
+
+	// raised is true if created by raise from an internal error, rather than by [Halt] explicitly
+	raised bool
+
+	definition *ErrorDefinition
+	messageID  string
+	args       []any
+
+	// callers where it was created, for origin in dev mode
+	callers haltCallers
 }
 
 func (h *haltError) Unwrap() error {
@@ -89,12 +131,26 @@ func (h *haltError) ExtractExtras(m map[string]any) {
 	}
 }
 
+// Is matches the [ErrorDefinition] creating it
+func (h *haltError) Is(target error) bool {
+	return h.definition != nil && target == h.definition
+}
+
+func (h *haltError) ProblemType() string {
+	return h.problemType
+}
+
+func (h *haltError) ProblemTitle() string {
+	return h.problemTitle
+}
+
 // NewHaltError create a new [HaltError]
 func NewHaltError(err error, opts ...HaltOption) error {
 	he := &haltError{
 		error:      err,
 		statusCode: http.StatusInternalServerError,
 	}
+	he.callers.capture(1)
 	for _, opt := range opts {
 		opt(he)
 	}
@@ -106,48 +162,196 @@ func Halt(err error, opts ...HaltOption) {
 	panic(NewHaltError(err, opts...))
 }
 
+// raise panic with err as an intentional [HaltError], status code and extras of err are kept
+func raise(err error) {
+	if _, ok := err.(*haltError); ok {
+		panic(err)
+	}
+	he := NewHaltError(err, HaltWithStatusCode(StatusCodeFromError(err))).(*haltError)
+	he.raised = true
+	panic(he)
+}
+
+// haltedExplicitly check if err is or wraps a [HaltError] created by [Halt] or [NewHaltError] explicitly,
+// a multi-error is explicit only if all of its errors are
+func haltedExplicitly(err error) bool {
+	if he, ok := err.(*haltError); ok && !he.raised {
+		return true
+	}
+	switch eu := err.(type) {
+	case withUnwrap:
+		if e := eu.Unwrap(); e != nil {
+			return haltedExplicitly(e)
+		}
+	case withUnwrapMulti:
+		errs := eu.Unwrap()
+		for _, e := range errs {
+			if e != nil && !haltedExplicitly(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	return false
+}
+
 // HaltString panic with [NewHaltError] and [errors.New]
 func HaltString(s string, opts ...HaltOption) {
 	Halt(errors.New(s), opts...)
 }
 
-// StatusCodeFromError get status code from previous created [HaltError]
-func StatusCodeFromError(err error) int {
-	for {
-		if err == nil {
-			return http.StatusInternalServerError
+// PanicError a panic recovered by [Context.Perform] that is not from [Halt], with stack trace
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	if err, ok := e.Value.(error); ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// errorFromRecovered convert a recovered value to error, reporting whether it's a real panic
+func errorFromRecovered(r any) (err error, recovered bool) {
+	if he, ok := r.(*haltError); ok {
+		return he, false
+	}
+	return &PanicError{Value: r, Stack: debug.Stack()}, true
+}
+
+// StatusMapper map an error not having a StatusCode() method to status code, like a third-party error exposing
+// HTTPStatus() or a gRPC status, ok is false if err is unknown to it
+type StatusMapper func(err error) (code int, ok bool)
+
+var (
+	statusMappersLock sync.RWMutex
+	statusMappers     []StatusMapper
+)
+
+// RegisterStatusMapper register a [StatusMapper] used by [StatusCodeFromError], mappers are consulted in order
+// of registration for every error in the tree
+//
+// It is intended to be called in init functions
+func RegisterStatusMapper(fn StatusMapper) {
+	statusMappersLock.Lock()
+	defer statusMappersLock.Unlock()
+	statusMappers = append(statusMappers, fn)
+}
+
+// mapStatusCode returns status code of a single error, without unwrapping
+func mapStatusCode(err error) (int, bool) {
+	if eh, ok := err.(withStatusCode); ok {
+		return eh.StatusCode(), true
+	}
+
+	statusMappersLock.RLock()
+	defer statusMappersLock.RUnlock()
+
+	for _, fn := range statusMappers {
+		if code, ok := fn(err); ok {
+			return code, true
 		}
-		if eh, ok := err.(withStatusCode); ok {
-			return eh.StatusCode()
+	}
+	return 0, false
+}
+
+// statusCodeOf returns status code of an error tree, see [StatusCodeFromError]
+func statusCodeOf(err error) (code int, ok bool) {
+	if code, ok = mapStatusCode(err); ok {
+		return
+	}
+	switch eu := err.(type) {
+	case withUnwrap:
+		if e := eu.Unwrap(); e != nil {
+			return statusCodeOf(e)
 		}
-		if eu, ok := err.(withUnwrap); ok {
-			err = eu.Unwrap()
-		} else {
-			break
+	case withUnwrapMulti:
+		for _, e := range eu.Unwrap() {
+			if e == nil {
+				continue
+			}
+			if c, found := statusCodeOf(e); found && (!ok || c > code) {
+				code, ok = c, true
+			}
 		}
 	}
-	return http.StatusInternalServerError
+	return
 }
 
-// BodyFromError extract extras from previous created [HaltError]
-func BodyFromError(err error) (m map[string]any) {
-	for {
-		if err == nil {
-			return
+// unwrapError returns errors directly wrapped by err, errors of a multi-error are ordered by status code
+// descending, errors without status code come last
+func unwrapError(err error) []error {
+	switch eu := err.(type) {
+	case withUnwrap:
+		if e := eu.Unwrap(); e != nil {
+			return []error{e}
 		}
-		if m == nil {
-			m = map[string]any{
-				HaltExtraKeyMessage: err.Error(),
-			}
+	case withUnwrapMulti:
+		type ranked struct {
+			err  error
+			code int
 		}
-		if eh, ok := err.(withExtract); ok {
-			eh.ExtractExtras(m)
+		var items []ranked
+		for _, e := range eu.Unwrap() {
+			if e == nil {
+				continue
+			}
+			code, _ := statusCodeOf(e)
+			items = append(items, ranked{err: e, code: code})
 		}
-		if eu, ok := err.(withUnwrap); ok {
-			err = eu.Unwrap()
-		} else {
-			break
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].code > items[j].code
+		})
+		errs := make([]error, 0, len(items))
+		for _, item := range items {
+			errs = append(errs, item.err)
 		}
+		return errs
+	}
+	return nil
+}
+
+// StatusCodeFromError get status code from previous created [HaltError], or from errors mapped by [StatusMapper],
+// defaults to [http.StatusInternalServerError]
+//
+// Both Unwrap() error and Unwrap() []error are followed, an outer error wins over errors it wraps, and the highest
+// status code wins among errors of a multi-error, so a server error is never hidden by a client error
+func StatusCodeFromError(err error) int {
+	if code, ok := statusCodeOf(err); ok {
+		return code
 	}
+	return http.StatusInternalServerError
+}
+
+// BodyFromError extract extras from previous created [HaltError]
+//
+// Both Unwrap() error and Unwrap() []error are followed, extras of an inner error override the outer ones, and among
+// errors of a multi-error, the one deciding status code in [StatusCodeFromError] wins conflicting extras
+func BodyFromError(err error) (m map[string]any) {
+	if err == nil {
+		return
+	}
+	m = map[string]any{
+		HaltExtraKeyMessage: err.Error(),
+	}
+	extractExtras(err, m)
 	return
 }
+
+// extractExtras extract extras of an error tree into m, errors visited later override
+func extractExtras(err error, m map[string]any) {
+	if eh, ok := err.(withExtract); ok {
+		eh.ExtractExtras(m)
+	}
+	errs := unwrapError(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		extractExtras(errs[i], m)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build go1.20
+
+package summer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestErrorTree(t *testing.T) {
+	badRequest := NewHaltError(errors.New("bad request"), HaltWithBadRequest(), HaltWithExtras(map[string]any{"field": "name", "side": "client"}))
+	unavailable := NewHaltError(errors.New("unavailable"), HaltWithStatusCode(http.StatusServiceUnavailable), HaltWithExtra("side", "server"), HaltWithType("https://example.com/probs/unavailable"))
+
+	err := fmt.Errorf("wrapped: %w", errors.Join(errors.New("plain"), badRequest, unavailable))
+	require.Equal(t, http.StatusServiceUnavailable, StatusCodeFromError(err))
+	require.Equal(t, map[string]any{"message": err.Error(), "field": "name", "side": "server"}, BodyFromError(err))
+	require.Equal(t, "https://example.com/probs/unavailable", ProblemFromError(err, "")["type"])
+	require.Equal(t, "Service Unavailable", ProblemFromError(err, "")["title"])
+
+	err = fmt.Errorf("%w and %w", badRequest, errors.New("plain"))
+	require.Equal(t, http.StatusBadRequest, StatusCodeFromError(err))
+
+	// outer error wins over errors it wraps
+	err = NewHaltError(errors.Join(unavailable), HaltWithStatusCode(http.StatusBadGateway))
+	require.Equal(t, http.StatusBadGateway, StatusCodeFromError(err))
+
+	require.Equal(t, http.StatusInternalServerError, StatusCodeFromError(errors.Join(errors.New("plain"))))
+
+	require.True(t, haltedExplicitly(errors.Join(badRequest, unavailable)))
+	require.False(t, haltedExplicitly(errors.Join(badRequest, errors.New("plain"))))
+}
+
+func TestErrorTreeDefinition(t *testing.T) {
+	err := errors.Join(errors.New("plain"), errDefTestLocked.New())
+	require.True(t, errors.Is(err, errDefTestLocked))
+	require.Equal(t, http.StatusLocked, StatusCodeFromError(err))
+	id, _, ok := messageIDFromError(err)
+	require.True(t, ok)
+	require.Equal(t, "errordef_test.locked", id)
+}
@@ -2,6 +2,7 @@ package summer
 
 import (
 	"errors"
+	"fmt"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"testing"
@@ -74,3 +75,53 @@ func TesPanicAny(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, StatusCodeFromError(err))
 	require.Equal(t, map[string]any{"message": "panic: TEST1"}, m)
 }
+
+func TestProblemFromError(t *testing.T) {
+	err := NewHaltError(
+		NewHaltError(errors.New("insufficient credit"), HaltWithType("https://example.com/probs/inner"), HaltWithTitle("Inner")),
+		HaltWithStatusCode(http.StatusForbidden),
+		HaltWithType("https://example.com/probs/out-of-credit"),
+		HaltWithExtra("balance", 30),
+		HaltWithExtra("status", "ignored"),
+	)
+	require.Equal(t, map[string]any{
+		"type":     "https://example.com/probs/out-of-credit",
+		"title":    "Inner",
+		"status":   http.StatusForbidden,
+		"detail":   "insufficient credit",
+		"instance": "/account/12345",
+		"balance":  30,
+	}, ProblemFromError(err, "/account/12345"))
+
+	require.Equal(t, map[string]any{
+		"type":   "about:blank",
+		"title":  "Internal Server Error",
+		"status": http.StatusInternalServerError,
+		"detail": "boom",
+	}, ProblemFromError(errors.New("boom"), ""))
+}
+
+type errorTestHTTPStatus struct {
+	status int
+}
+
+func (e errorTestHTTPStatus) Error() string {
+	return "third-party error"
+}
+
+func (e errorTestHTTPStatus) HTTPStatus() int {
+	return e.status
+}
+
+func TestRegisterStatusMapper(t *testing.T) {
+	err := fmt.Errorf("calling upstream: %w", errorTestHTTPStatus{status: http.StatusGatewayTimeout})
+	require.Equal(t, http.StatusInternalServerError, StatusCodeFromError(err))
+
+	RegisterStatusMapper(func(err error) (int, bool) {
+		if e, ok := err.(interface{ HTTPStatus() int }); ok {
+			return e.HTTPStatus(), true
+		}
+		return 0, false
+	})
+	require.Equal(t, http.StatusGatewayTimeout, StatusCodeFromError(err))
+}
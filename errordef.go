@@ -0,0 +1,118 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	HaltExtraKeyCode = "code"
+)
+
+// ErrorDefinition a registered error with a stable machine-readable code, HTTP status and default message template,
+// created by [DefineError]
+//
+// Errors created by [ErrorDefinition.New] carry the code in key "code" of error body, and match the definition
+// with [errors.Is]
+type ErrorDefinition struct {
+	code       string
+	statusCode int
+	message    string
+}
+
+var (
+	errorDefinitionsLock sync.Mutex
+	errorDefinitions     = map[string]*ErrorDefinition{}
+)
+
+// DefineError register an [ErrorDefinition], message is a template formatted with arguments of
+// [ErrorDefinition.New] like [fmt.Sprintf]
+//
+// It is intended to be called in package level variable declarations, a duplicated code panics
+func DefineError(code string, statusCode int, message string) *ErrorDefinition {
+	if code == "" {
+		panic("empty error code")
+	}
+
+	errorDefinitionsLock.Lock()
+	defer errorDefinitionsLock.Unlock()
+
+	if _, ok := errorDefinitions[code]; ok {
+		panic("duplicated error code: " + code)
+	}
+	d := &ErrorDefinition{code: code, statusCode: statusCode, message: message}
+	errorDefinitions[code] = d
+	return d
+}
+
+// ErrorDefinitions returns all registered [ErrorDefinition], sorted by code
+func ErrorDefinitions() (defs []*ErrorDefinition) {
+	errorDefinitionsLock.Lock()
+	defer errorDefinitionsLock.Unlock()
+
+	for _, d := range errorDefinitions {
+		defs = append(defs, d)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].code < defs[j].code
+	})
+	return
+}
+
+// Code returns the stable code
+func (d *ErrorDefinition) Code() string {
+	return d.code
+}
+
+// StatusCode returns the HTTP status code
+func (d *ErrorDefinition) StatusCode() int {
+	return d.statusCode
+}
+
+// Message returns the default message template
+func (d *ErrorDefinition) Message() string {
+	return d.message
+}
+
+// Error returns the code, a definition is an error only to be the target of [errors.Is]
+func (d *ErrorDefinition) Error() string {
+	return d.code
+}
+
+// MarshalJSON marshal as an entry of error catalogue, with keys "code", "status" and "message"
+func (d *ErrorDefinition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		HaltExtraKeyCode: d.code,
+		"status":         d.statusCode,
+		"message":        d.message,
+	})
+}
+
+// format returns message formatted with args, the template is kept as is without args
+func (d *ErrorDefinition) format(args []any) string {
+	if len(args) == 0 {
+		return d.message
+	}
+	return fmt.Sprintf(d.message, args...)
+}
+
+// New create a [HaltError] with status code, code and message formatted with args
+func (d *ErrorDefinition) New(args ...any) error {
+	return NewHaltError(
+		errors.New(d.format(args)),
+		HaltWithStatusCode(d.statusCode),
+		HaltWithExtra(HaltExtraKeyCode, d.code),
+		func(h *haltError) {
+			h.definition = d
+			h.args = args
+		},
+	)
+}
+
+// Halt panic with [ErrorDefinition.New]
+func (d *ErrorDefinition) Halt(args ...any) {
+	panic(d.New(args...))
+}
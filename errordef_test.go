@@ -0,0 +1,68 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var (
+	errDefTestNotFound = DefineError("errordef_test.not_found", http.StatusNotFound, "user %s not found")
+	errDefTestLocked   = DefineError("errordef_test.locked", http.StatusLocked, "account locked")
+)
+
+func TestDefineError(t *testing.T) {
+	require.Panics(t, func() {
+		DefineError("errordef_test.not_found", http.StatusNotFound, "again")
+	})
+	require.Panics(t, func() {
+		DefineError("", http.StatusNotFound, "empty")
+	})
+
+	defs := ErrorDefinitions()
+	require.Contains(t, defs, errDefTestNotFound)
+	require.Contains(t, defs, errDefTestLocked)
+	for i := 1; i < len(defs); i++ {
+		require.Less(t, defs[i-1].Code(), defs[i].Code())
+	}
+
+	buf, err := json.Marshal(errDefTestNotFound)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"code":"errordef_test.not_found","status":404,"message":"user %s not found"}`, string(buf))
+}
+
+func TestErrorDefinitionNew(t *testing.T) {
+	err := errDefTestNotFound.New("alice")
+	require.Equal(t, "user alice not found", err.Error())
+	require.Equal(t, http.StatusNotFound, StatusCodeFromError(err))
+	require.Equal(t, map[string]any{"message": "user alice not found", "code": "errordef_test.not_found"}, BodyFromError(err))
+
+	require.True(t, errors.Is(err, errDefTestNotFound))
+	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", err), errDefTestNotFound))
+	require.False(t, errors.Is(err, errDefTestLocked))
+	require.False(t, errors.Is(NewHaltError(errors.New("user alice not found")), errDefTestNotFound))
+
+	require.Equal(t, "account locked", errDefTestLocked.New().Error())
+}
+
+func TestErrorDefinitionHalt(t *testing.T) {
+	a := Basic(WithProductionMode(true))
+	a.HandleFunc("/locked", func(c Context) {
+		errDefTestLocked.Halt()
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/locked", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusLocked, rw.Code)
+	require.JSONEq(t, `{"message":"account locked","code":"errordef_test.locked"}`, rw.Body.String())
+
+	doc := a.OpenAPI()
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	code := schemas["HaltError"].(map[string]any)["properties"].(map[string]any)["code"].(map[string]any)
+	require.Contains(t, code["enum"], "errordef_test.locked")
+	require.Contains(t, doc["x-error-codes"], errDefTestLocked)
+}
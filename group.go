@@ -0,0 +1,242 @@
+package summer
+
+import (
+	"context"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Middleware wraps a [HandlerFunc] with cross-cutting logic, like authentication and auditing
+type Middleware[T Context] func(h HandlerFunc[T]) HandlerFunc[T]
+
+// chainMiddlewares wraps h with mws, the first one is the outermost
+func chainMiddlewares[T Context](h HandlerFunc[T], mws []Middleware[T]) HandlerFunc[T] {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Group a set of routes sharing a path prefix and middlewares
+type Group[T Context] interface {
+	// HandleFunc register an action function with given path pattern
+	//
+	// This function is similar with [http.ServeMux.HandleFunc], pattern can be qualified with a method,
+	// and can capture path segments, which are available in [Context.Bind] with prefix "path_"
+	//
+	// example:
+	//
+	//	a.HandleFunc("GET /users/{id}", actionGetUser)
+	//	a.HandleFunc("/files/{path...}", actionFiles)
+	//	a.HandleFunc("/static/", actionStatic)
+	//
+	// It panics if pattern is invalid, or conflicts with a previous registered one
+	//
+	// Additional middlewares only apply to this route, see [Group.Use] for the execution order
+	HandleFunc(pattern string, fn HandlerFunc[T], mws ...Middleware[T])
+
+	// Use append middlewares applied to all routes in this group, including the ones registered before
+	//
+	// For every request, [Registry.Inject] runs first, then middlewares from [App.Use], then middlewares of
+	// nested groups from the outermost to the innermost, then route middlewares, and finally the handler.
+	// All of them run inside [Context.Perform], so [Halt] from any middleware produces the normal error response
+	// and skips the rest of the chain.
+	//
+	// Middlewares are composed once per route on its first request, Use panics after the first request is served
+	Use(mws ...Middleware[T])
+
+	// Group create a sub group with path prefix and middlewares, sharing the same [ContextFactory]
+	//
+	// example:
+	//
+	//	v1 := a.Group("/api/v1", middlewareAuth)
+	//	v1.HandleFunc("GET /users/{id}", actionGetUser) // GET /api/v1/users/{id}
+	Group(prefix string, mws ...Middleware[T]) Group[T]
+
+	// Mount serve all requests under path prefix with a [http.Handler], prefix is stripped from the request path
+	//
+	// Any [http.Handler] can be mounted, including another [App]
+	Mount(prefix string, h http.Handler)
+
+	// Static serve files from fsys under path prefix with GET and HEAD, through the same middlewares as other routes
+	//
+	// Byte ranges, conditional requests and precompressed siblings are supported, see [Context.SendFileFS].
+	// Directory listing is disabled by default, see [StaticWithListing] and [StaticWithSPA]
+	//
+	// example:
+	//
+	//	//go:embed dist
+	//	var dist embed.FS
+	//
+	//	a.Static("/admin", rg.Must(fs.Sub(dist, "dist")), summer.StaticWithSPA())
+	Static(prefix string, fsys fs.FS, opts ...StaticOption)
+}
+
+type group[T Context] struct {
+	app    *app[T]
+	parent *group[T]
+	prefix string
+	mws    []Middleware[T]
+}
+
+// cleanGroupPrefix validates a group prefix and removes the trailing slash
+func cleanGroupPrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, " \t") {
+		panic("invalid group prefix: " + prefix)
+	}
+	return strings.TrimSuffix(prefix, "/")
+}
+
+// composeRoutePattern prepend prefix to path of pattern, path must start with '/' like [parseRoutePattern] requires
+func composeRoutePattern(prefix string, pattern string) string {
+	pattern = strings.TrimSpace(pattern)
+	method, path := "", pattern
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		method, path = pattern[:i]+" ", strings.TrimSpace(pattern[i+1:])
+	}
+	if !strings.HasPrefix(path, "/") {
+		panic("route pattern must start with '/': " + pattern)
+	}
+	return method + prefix + path
+}
+
+func (g *group[T]) Use(mws ...Middleware[T]) {
+	if atomic.LoadInt32(&g.app.serving) != 0 {
+		panic("middlewares must be registered before serving requests")
+	}
+	g.mws = append(g.mws, mws...)
+}
+
+func (g *group[T]) Group(prefix string, mws ...Middleware[T]) Group[T] {
+	return &group[T]{
+		app:    g.app,
+		parent: g,
+		prefix: g.prefix + cleanGroupPrefix(prefix),
+		mws:    mws,
+	}
+}
+
+// wrap wraps h with middlewares of this group and all parent groups
+func (g *group[T]) wrap(h HandlerFunc[T]) HandlerFunc[T] {
+	for ; g != nil; g = g.parent {
+		h = chainMiddlewares(h, g.mws)
+	}
+	return h
+}
+
+// mountDoc a handler mounted by [Group.Mount]
+type mountDoc struct {
+	prefix string
+	h      http.Handler
+}
+
+// typedGroup is implemented by group, allowing [HandleTyped] to record request and response types
+type typedGroup[T Context] interface {
+	handle(pattern string, fn HandlerFunc[T], mws []Middleware[T], reqType, respType reflect.Type)
+}
+
+func (g *group[T]) HandleFunc(pattern string, fn HandlerFunc[T], mws ...Middleware[T]) {
+	g.handle(pattern, fn, mws, nil, nil)
+}
+
+func (g *group[T]) handle(pattern string, fn HandlerFunc[T], mws []Middleware[T], reqType, respType reflect.Type) {
+	method, path := g.register(pattern, fn, mws)
+
+	g.app.routeDocs = append(g.app.routeDocs, routeDoc{
+		method: method,
+		path:   path,
+		req:    reqType,
+		resp:   respType,
+	})
+}
+
+// register register a route without documenting it
+func (g *group[T]) register(pattern string, fn HandlerFunc[T], mws []Middleware[T]) (method string, path string) {
+	return g.registerComposed(composeRoutePattern(g.prefix, pattern), fn, mws)
+}
+
+// registerComposed register a route with pattern already prefixed by [composeRoutePattern]
+func (g *group[T]) registerComposed(pattern string, fn HandlerFunc[T], mws []Middleware[T]) (method string, path string) {
+	method, path, _, _ = parseRoutePattern(pattern)
+
+	fn = chainMiddlewares(fn, mws)
+
+	a := g.app
+
+	// composed lazily, middlewares added by [Group.Use] after registration are included
+	var (
+		h    HandlerFunc[T]
+		once sync.Once
+	)
+
+	g.app.router.Handle(
+		pattern,
+		withMountedRouteTag(
+			path,
+			http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				c := a.cf(rw, req)
+				func() {
+					defer c.Perform()
+					a.Inject(c)
+					once.Do(func() {
+						h = g.wrap(fn)
+					})
+					h(c)
+				}()
+			}),
+		),
+	)
+	return
+}
+
+func (g *group[T]) Mount(prefix string, h http.Handler) {
+	prefix = g.prefix + cleanGroupPrefix(prefix)
+
+	_, _, segs, err := parseRoutePattern(prefix + "/")
+	if err != nil {
+		panic(err.Error())
+	}
+	// strip segments of prefix, excluding the trailing wildcard
+	n := len(segs) - 1
+
+	handler := withMountedRouteTag(
+		prefix+"/",
+		http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			segs := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", n+1)
+
+			r := req.Clone(context.WithValue(req.Context(), contextKeyMountPrefix, mountPrefixFromContext(req.Context())+prefix))
+			r.URL.Path = "/"
+			if len(segs) > n {
+				r.URL.Path += segs[n]
+			}
+			r.URL.RawPath = ""
+
+			h.ServeHTTP(rw, r)
+		}),
+	)
+
+	g.app.router.Handle(prefix+"/", handler)
+	if prefix != "" {
+		g.app.router.Handle(prefix, handler)
+	}
+
+	g.app.mounts = append(g.app.mounts, mountDoc{prefix: prefix, h: h})
+}
+
+// mountPrefixFromContext returns the composed prefix of all [Group.Mount] this request went through
+func mountPrefixFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(contextKeyMountPrefix).(string)
+	return s
+}
+
+// withMountedRouteTag similar with [otelhttp.WithRouteTag], but prepends prefix from [Group.Mount]
+func withMountedRouteTag(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		otelhttp.WithRouteTag(mountPrefixFromContext(req.Context())+route, h).ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,98 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestComposeRoutePattern(t *testing.T) {
+	require.Equal(t, "GET /api/v1/users/{id}", composeRoutePattern("/api/v1", "GET /users/{id}"))
+	require.Equal(t, "/api/v1/", composeRoutePattern("/api/v1", "/"))
+	require.Equal(t, "/users", composeRoutePattern("", "/users"))
+	require.Panics(t, func() { composeRoutePattern("/api", "users") })
+	require.Panics(t, func() { composeRoutePattern("/api", "GET users") })
+
+	a := Basic()
+	require.Panics(t, func() {
+		a.Group("/api").HandleFunc("users", func(c Context) {})
+	})
+}
+
+func TestGroup(t *testing.T) {
+	a := Basic()
+
+	var trace []string
+
+	mw := func(name string) Middleware[Context] {
+		return func(h HandlerFunc[Context]) HandlerFunc[Context] {
+			return func(c Context) {
+				trace = append(trace, name)
+				h(c)
+			}
+		}
+	}
+
+	a.Use(mw("app"))
+
+	v1 := a.Group("/api/v1/", mw("v1"))
+	v1.HandleFunc("GET /users/{id}", func(c Context) {
+		c.Text(Bind[struct {
+			ID string `json:"path_id"`
+		}](c).ID)
+	}, mw("route"))
+
+	tenant := v1.Group("/tenants/{tenant}", mw("tenant"))
+	tenant.HandleFunc("/info", func(c Context) {
+		c.Text(Bind[struct {
+			Tenant string `json:"path_tenant"`
+		}](c).Tenant)
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/api/v1/users/1", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "1", rw.Body.String())
+	require.Equal(t, []string{"app", "v1", "route"}, trace)
+
+	trace = nil
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/api/v1/tenants/t1/info", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "t1", rw.Body.String())
+	require.Equal(t, []string{"app", "v1", "tenant"}, trace)
+
+	require.Panics(t, func() { a.Group("api") })
+}
+
+func TestGroupMount(t *testing.T) {
+	sub := Basic()
+	sub.HandleFunc("GET /users/{id}", func(c Context) {
+		args := Bind[struct {
+			Tenant string `json:"path_tenant"`
+			ID     string `json:"path_id"`
+		}](c)
+		c.Text(args.Tenant + "/" + args.ID)
+	})
+
+	a := Basic()
+	a.Mount("/tenants/{tenant}", sub)
+	a.Mount("/raw", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		respondInternal(rw, req.URL.Path, http.StatusOK)
+	}))
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/tenants/t1/users/2", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "t1/2", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/raw", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "/", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/raw/a/b", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, "/a/b", rw.Body.String())
+}
@@ -0,0 +1,154 @@
+package summer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HaltWithMessageID a [HaltOption] setting message ID and template arguments, for localized message from catalogs
+// registered by [WithMessages] or [WithMessagesFS]
+//
+// Errors created by [ErrorDefinition.New] use the code as message ID
+func HaltWithMessageID(id string, args ...any) HaltOption {
+	return func(h *haltError) {
+		h.messageID = id
+		h.args = args
+	}
+}
+
+// messageIDFromError returns message ID and template arguments of the outermost [HaltError] having one,
+// errors of a multi-error are visited in the order of [StatusCodeFromError] precedence
+func messageIDFromError(err error) (id string, args []any, ok bool) {
+	if he, isHalt := err.(*haltError); isHalt {
+		if he.messageID != "" {
+			return he.messageID, he.args, true
+		}
+		if he.definition != nil {
+			return he.definition.code, he.args, true
+		}
+	}
+	for _, e := range unwrapError(err) {
+		if id, args, ok = messageIDFromError(e); ok {
+			return
+		}
+	}
+	return
+}
+
+// normalizeLocale normalize a language tag like "zh_cn" to "zh-cn" for case-insensitive matching
+func normalizeLocale(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "_", "-"))
+}
+
+// parseAcceptLanguage returns language ranges of Accept-Language header, ordered by quality value,
+// ranges with q=0 are excluded
+func parseAcceptLanguage(header string) (tags []string) {
+	type languageRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []languageRange
+	for _, item := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(item, ";")
+		r := languageRange{tag: normalizeLocale(tag), q: 1}
+		if r.tag == "" {
+			continue
+		}
+		if k, v, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(k) == "q" {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				r.q = f
+			}
+		}
+		if r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	for _, r := range ranges {
+		tags = append(tags, r.tag)
+	}
+	return
+}
+
+// lookupMessage find message of id in catalogs with language ranges, a range is truncated from the end until
+// matching, like "zh-hant-tw", "zh-hant" and "zh"
+func lookupMessage(catalogs map[string]map[string]string, tags []string, id string) (string, bool) {
+	for _, tag := range tags {
+		for tag != "" && tag != "*" {
+			if s, ok := catalogs[tag][id]; ok {
+				return s, true
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return "", false
+}
+
+// Localize returns message of id in catalogs registered by [WithMessages] or [WithMessagesFS], formatted with args
+// like [fmt.Sprintf], locale is picked from header set by [WithLocaleHeader] or Accept-Language header
+func Localize(c Context, id string, args ...any) (s string, ok bool) {
+	opts := optionsFromContext(c)
+	if len(opts.messages) == 0 {
+		return
+	}
+	var tags []string
+	if opts.localeHeader != "" {
+		tags = parseAcceptLanguage(c.Req().Header.Get(opts.localeHeader))
+	}
+	tags = append(tags, parseAcceptLanguage(c.Req().Header.Get("Accept-Language"))...)
+	if s, ok = lookupMessage(opts.messages, tags, id); ok && len(args) > 0 {
+		s = fmt.Sprintf(s, args...)
+	}
+	return
+}
+
+// localizeError returns localized message of error with message ID, see [HaltWithMessageID]
+func (c *basicContext) localizeError(err error) (string, bool) {
+	if len(c.opts.messages) == 0 {
+		return "", false
+	}
+	if c.opts.localeHeader != "" {
+		addVary(c.rw.Header(), c.opts.localeHeader)
+	}
+	addVary(c.rw.Header(), "Accept-Language")
+
+	id, args, ok := messageIDFromError(err)
+	if !ok {
+		return "", false
+	}
+	return Localize(c, id, args...)
+}
+
+// loadMessagesFS load catalogs from JSON files in fsys matching pattern, locale is the file name without extension
+func loadMessagesFS(fsys fs.FS, pattern string) (catalogs map[string]map[string]string, err error) {
+	var names []string
+	if names, err = fs.Glob(fsys, pattern); err != nil {
+		return
+	}
+	catalogs = map[string]map[string]string{}
+	for _, name := range names {
+		var buf []byte
+		if buf, err = fs.ReadFile(fsys, name); err != nil {
+			return
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(buf, &messages); err != nil {
+			err = fmt.Errorf("invalid message catalog %s: %w", name, err)
+			return
+		}
+		base := path.Base(name)
+		catalogs[strings.TrimSuffix(base, path.Ext(base))] = messages
+	}
+	return
+}
@@ -0,0 +1,134 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+var errDefLocaleTestQuota = DefineError("locale_test.quota", http.StatusTooManyRequests, "quota of %s exceeded")
+
+func TestParseAcceptLanguage(t *testing.T) {
+	require.Equal(t, []string{"zh-cn", "en", "zh"}, parseAcceptLanguage("zh-CN, zh;q=0.8, en;q=0.9, fr;q=0"))
+	require.Nil(t, parseAcceptLanguage(""))
+}
+
+func TestLookupMessage(t *testing.T) {
+	catalogs := map[string]map[string]string{
+		"zh":      {"hello": "你好"},
+		"zh-hant": {"hello": "妳好"},
+	}
+	s, ok := lookupMessage(catalogs, []string{"zh-hant-tw"}, "hello")
+	require.True(t, ok)
+	require.Equal(t, "妳好", s)
+	s, ok = lookupMessage(catalogs, []string{"fr", "zh-cn"}, "hello")
+	require.True(t, ok)
+	require.Equal(t, "你好", s)
+	_, ok = lookupMessage(catalogs, []string{"en", "*"}, "hello")
+	require.False(t, ok)
+}
+
+func TestLoadMessagesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/zh-CN.json": {Data: []byte(`{"hello":"你好"}`)},
+		"locales/ja.json":    {Data: []byte(`{"hello":"こんにちは"}`)},
+		"locales/README.md":  {Data: []byte(`readme`)},
+		"broken/en.json":     {Data: []byte(`{`)},
+	}
+	catalogs, err := loadMessagesFS(fsys, "locales/*.json")
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]string{
+		"zh-CN": {"hello": "你好"},
+		"ja":    {"hello": "こんにちは"},
+	}, catalogs)
+
+	_, err = loadMessagesFS(fsys, "broken/*.json")
+	require.Error(t, err)
+	require.Panics(t, func() {
+		WithMessagesFS(fsys, "broken/*.json")
+	})
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	var observed []string
+
+	a := Basic(
+		WithMessagesFS(fstest.MapFS{
+			"zh-CN.json": {Data: []byte(`{"locale_test.quota":"%s 的配额已用尽","user_missing":"用户 %s 不存在"}`)},
+		}, "*.json"),
+		WithMessages("ja", map[string]string{"user_missing": "ユーザー %s が存在しません"}),
+		WithLocaleHeader("X-Locale"),
+		WithErrorObserver(func(c Context, err error, recovered bool) {
+			observed = append(observed, err.Error())
+		}),
+	)
+	a.HandleFunc("/user", func(c Context) {
+		HaltString("user alice not found", HaltWithStatusCode(http.StatusNotFound), HaltWithMessageID("user_missing", "alice"))
+	})
+	a.HandleFunc("/quota", func(c Context) {
+		errDefLocaleTestQuota.Halt("storage")
+	})
+	a.HandleFunc("/plain", func(c Context) {
+		HaltString("plain", HaltWithBadRequest())
+	})
+	a.HandleFunc("/hello", func(c Context) {
+		s, _ := Localize(c, "user_missing", "bob")
+		c.Text(s)
+	})
+
+	do := func(path string, header http.Header) (*httptest.ResponseRecorder, map[string]any) {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		a.ServeHTTP(rw, req)
+		var m map[string]any
+		_ = json.Unmarshal(rw.Body.Bytes(), &m)
+		return rw, m
+	}
+
+	rw, m := do("/user", http.Header{"Accept-Language": {"fr, zh-CN;q=0.8"}})
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	require.Equal(t, "用户 alice 不存在", m["message"])
+	require.Equal(t, []string{"X-Locale", "Accept-Language", "Accept"}, rw.Header().Values("Vary"))
+	require.Equal(t, "user alice not found", observed[0])
+
+	_, m = do("/user", http.Header{"Accept-Language": {"zh-CN"}, "X-Locale": {"ja"}})
+	require.Equal(t, "ユーザー alice が存在しません", m["message"])
+
+	_, m = do("/user", http.Header{"Accept-Language": {"en"}})
+	require.Equal(t, "user alice not found", m["message"])
+
+	_, m = do("/quota", http.Header{"Accept-Language": {"zh"}})
+	require.Equal(t, map[string]any{"message": "quota of storage exceeded", "code": "locale_test.quota"}, m)
+
+	_, m = do("/quota", http.Header{"Accept-Language": {"zh-cn"}})
+	require.Equal(t, map[string]any{"message": "storage 的配额已用尽", "code": "locale_test.quota"}, m)
+
+	_, m = do("/plain", http.Header{"Accept-Language": {"zh-CN"}})
+	require.Equal(t, "plain", m["message"])
+
+	rw, _ = do("/hello", http.Header{"X-Locale": {"ja_JP"}})
+	require.Equal(t, "ユーザー bob が存在しません", rw.Body.String())
+}
+
+func TestLocalizedProblemDetails(t *testing.T) {
+	a := Basic(
+		WithProblemDetails(),
+		WithMessages("zh", map[string]string{"user_missing": "用户不存在"}),
+	)
+	a.HandleFunc("/user", func(c Context) {
+		Halt(errors.New("user not found"), HaltWithStatusCode(http.StatusNotFound), HaltWithMessageID("user_missing"))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/user", nil)
+	req.Header.Set("Accept-Language", "zh-CN")
+	a.ServeHTTP(rw, req)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &m))
+	require.Equal(t, "用户不存在", m["detail"])
+}
@@ -0,0 +1,327 @@
+package summer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	errMsgpackShort   = errors.New("msgpack: unexpected end of data")
+	errMsgpackTooDeep = errors.New("msgpack: exceeded max depth")
+)
+
+// msgpackDecoder a minimal msgpack decoder producing values like [encoding/json] does with any
+type msgpackDecoder struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+// enter increase nesting depth before decoding elements of an array or a map, see [maxDecodeDepth]
+func (d *msgpackDecoder) enter() error {
+	if d.depth++; d.depth > maxDecodeDepth {
+		return errMsgpackTooDeep
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) next(n int) (b []byte, err error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		err = errMsgpackShort
+		return
+	}
+	b = d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return
+}
+
+func (d *msgpackDecoder) uint(n int) (v uint64, err error) {
+	var b []byte
+	if b, err = d.next(n); err != nil {
+		return
+	}
+	switch n {
+	case 1:
+		v = uint64(b[0])
+	case 2:
+		v = uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		v = uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		v = binary.BigEndian.Uint64(b)
+	}
+	return
+}
+
+func (d *msgpackDecoder) int(n int) (v int64, err error) {
+	var u uint64
+	if u, err = d.uint(n); err != nil {
+		return
+	}
+	switch n {
+	case 1:
+		v = int64(int8(u))
+	case 2:
+		v = int64(int16(u))
+	case 4:
+		v = int64(int32(u))
+	case 8:
+		v = int64(u)
+	}
+	return
+}
+
+func (d *msgpackDecoder) str(n int) (v any, err error) {
+	var b []byte
+	if b, err = d.next(n); err != nil {
+		return
+	}
+	v = string(b)
+	return
+}
+
+func (d *msgpackDecoder) bin(n int) (v any, err error) {
+	var b []byte
+	if b, err = d.next(n); err != nil {
+		return
+	}
+	v = append([]byte{}, b...)
+	return
+}
+
+func (d *msgpackDecoder) array(n int) (v any, err error) {
+	// every element takes at least 1 byte
+	if n < 0 || n > len(d.buf)-d.pos {
+		err = errMsgpackShort
+		return
+	}
+	if err = d.enter(); err != nil {
+		return
+	}
+	defer func() { d.depth-- }()
+	s := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		var item any
+		if item, err = d.decode(); err != nil {
+			return
+		}
+		s = append(s, item)
+	}
+	v = s
+	return
+}
+
+func (d *msgpackDecoder) mapping(n int) (v any, err error) {
+	// every key-value takes at least 2 bytes
+	if n < 0 || n*2 > len(d.buf)-d.pos {
+		err = errMsgpackShort
+		return
+	}
+	if err = d.enter(); err != nil {
+		return
+	}
+	defer func() { d.depth-- }()
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		var key, val any
+		if key, err = d.decode(); err != nil {
+			return
+		}
+		if val, err = d.decode(); err != nil {
+			return
+		}
+		if s, ok := key.(string); ok {
+			m[s] = val
+		} else {
+			m[fmt.Sprint(key)] = val
+		}
+	}
+	v = m
+	return
+}
+
+func (d *msgpackDecoder) ext(n int) (v any, err error) {
+	var (
+		typ  int64
+		data []byte
+	)
+	if typ, err = d.int(1); err != nil {
+		return
+	}
+	if data, err = d.next(n); err != nil {
+		return
+	}
+	// timestamp extension
+	if typ == -1 {
+		switch len(data) {
+		case 4:
+			v = time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC()
+			return
+		case 8:
+			u := binary.BigEndian.Uint64(data)
+			v = time.Unix(int64(u&0x3ffffffff), int64(u>>34)).UTC()
+			return
+		case 12:
+			v = time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data[:4]))).UTC()
+			return
+		}
+	}
+	v = append([]byte{}, data...)
+	return
+}
+
+func (d *msgpackDecoder) decode() (v any, err error) {
+	var b []byte
+	if b, err = d.next(1); err != nil {
+		return
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.mapping(int(c & 0x0f))
+	}
+
+	var n uint64
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		return d.int(1 << (c - 0xd0))
+	case 0xca:
+		if n, err = d.uint(4); err != nil {
+			return
+		}
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb:
+		if n, err = d.uint(8); err != nil {
+			return
+		}
+		return math.Float64frombits(n), nil
+	case 0xd9, 0xda, 0xdb:
+		if n, err = d.uint(1 << (c - 0xd9)); err != nil {
+			return
+		}
+		return d.str(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		if n, err = d.uint(1 << (c - 0xc4)); err != nil {
+			return
+		}
+		return d.bin(int(n))
+	case 0xdc, 0xdd:
+		if n, err = d.uint(2 << (c - 0xdc)); err != nil {
+			return
+		}
+		return d.array(int(n))
+	case 0xde, 0xdf:
+		if n, err = d.uint(2 << (c - 0xde)); err != nil {
+			return
+		}
+		return d.mapping(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		if n, err = d.uint(1 << (c - 0xc7)); err != nil {
+			return
+		}
+		return d.ext(int(n))
+	}
+
+	err = fmt.Errorf("msgpack: invalid code 0x%02x", c)
+	return
+}
+
+// appendMsgpack encode a value produced by [genericValue] as msgpack
+func appendMsgpack(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendMsgpackInt(b, i)
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+		}
+		f, _ := v.Float64()
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+	case string:
+		b = appendMsgpackLength(b, len(v), 0xa0, 31, 0xd9, 0xda)
+		return append(b, v...)
+	case []any:
+		b = appendMsgpackLength(b, len(v), 0x90, 15, 0, 0xdc)
+		for _, item := range v {
+			b = appendMsgpack(b, item)
+		}
+		return b
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackLength(b, len(v), 0x80, 15, 0, 0xde)
+		for _, k := range keys {
+			b = appendMsgpack(b, k)
+			b = appendMsgpack(b, v[k])
+		}
+		return b
+	}
+	return append(b, 0xc0)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(int16(i)))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(int32(i)))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+// appendMsgpackLength append header of str, array or map, with the fixed form, 8-bit form if code8 is not 0,
+// 16-bit form, and 32-bit form following the 16-bit one
+func appendMsgpackLength(b []byte, n int, fix byte, fixMax int, code8 byte, code16 byte) []byte {
+	switch {
+	case n <= fixMax:
+		return append(b, fix|byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		return append(b, code8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, code16+1), uint32(n))
+}
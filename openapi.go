@@ -0,0 +1,374 @@
+package summer
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// routeDoc describes a registered route for OpenAPI document generation
+type routeDoc struct {
+	method string
+	path   string
+	req    reflect.Type
+	resp   reflect.Type
+}
+
+// openAPIRouteSource is implemented by [App], allowing a mounted [App] to contribute its routes
+type openAPIRouteSource interface {
+	openAPIRoutes() []routeDoc
+}
+
+var (
+	openAPIAnyMethods = []string{
+		http.MethodGet,
+		http.MethodPut,
+		http.MethodPost,
+		http.MethodDelete,
+		http.MethodPatch,
+	}
+
+	typeTime = reflect.TypeOf(time.Time{})
+)
+
+// openAPIPath convert a route path to OpenAPI path template
+func openAPIPath(path string) string {
+	path = strings.ReplaceAll(path, "...}", "}")
+	path = strings.TrimSuffix(path, "{$}")
+	return path
+}
+
+// openAPIOperationID create an operation id from method and path, like "getUsersId"
+func openAPIOperationID(method string, path string) string {
+	sb := &strings.Builder{}
+	sb.WriteString(strings.ToLower(method))
+	upper := true
+	for _, c := range path {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			if upper && c >= 'a' && c <= 'z' {
+				c = c - 'a' + 'A'
+			}
+			sb.WriteRune(c)
+			upper = false
+		} else {
+			upper = true
+		}
+	}
+	return sb.String()
+}
+
+type openAPISchemas struct {
+	names      map[reflect.Type]string
+	components map[string]any
+}
+
+func (s *openAPISchemas) name(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	base := strings.Map(func(c rune) rune {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '.' {
+			return c
+		}
+		return '_'
+	}, t.Name())
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := s.components[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	s.names[t] = name
+	return name
+}
+
+// schema create a JSON schema for t, named structs are stored in components and referenced
+func (s *openAPISchemas) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == typeTime {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(jsonFieldsOf(t))
+		}
+		if _, ok := s.names[t]; !ok {
+			name := s.name(t)
+			// placeholder for recursive types
+			s.components[name] = map[string]any{}
+			s.components[name] = s.object(jsonFieldsOf(t))
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + s.names[t]}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object create an object schema from fields
+func (s *openAPISchemas) object(fields []jsonField) map[string]any {
+	props := map[string]any{}
+	for _, f := range fields {
+		props[f.name] = s.field(f)
+	}
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (s *openAPISchemas) field(f jsonField) map[string]any {
+	if f.asString {
+		return map[string]any{"type": "string"}
+	}
+	return s.schema(f.typ)
+}
+
+// operation create an OpenAPI operation object for a route and method
+func (s *openAPISchemas) operation(method string, rd routeDoc) map[string]any {
+	op := map[string]any{
+		"operationId": openAPIOperationID(method, rd.path),
+	}
+
+	var (
+		params []any
+		body   []jsonField
+	)
+
+	// templated path parameters are always required, path_ fields only refine their schemas
+	pathParams := map[string]map[string]any{}
+	if _, _, segs, err := parseRoutePattern(rd.path); err == nil {
+		for _, seg := range segs {
+			if seg.kind == routeSegmentStatic || seg.value == "" {
+				continue
+			}
+			p := map[string]any{
+				"name":     seg.value,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			}
+			pathParams[seg.value] = p
+			params = append(params, p)
+		}
+	}
+
+	if rd.req != nil {
+		t := rd.req
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			for _, f := range bindFieldsOf(t) {
+				var in, name string
+				switch f.source {
+				case bindSourcePath:
+					in, name = "path", f.key
+				case bindSourceQuery:
+					in, name = "query", f.key
+				case bindSourceHeader:
+					in, name = "header", strings.ReplaceAll(f.headerName, "_", "-")
+				case bindSourceCookie:
+					in, name = "cookie", f.key
+				case bindSourceNone:
+					if method == http.MethodGet || method == http.MethodDelete {
+						in, name = "query", f.name
+						break
+					}
+					fallthrough
+				default:
+					body = append(body, f.jsonField)
+					continue
+				}
+				schema := s.field(f.jsonField)
+				if f.hasDefault {
+					v := reflect.New(f.typ).Elem()
+					if setFromString(v, f.defaultValue) == nil {
+						schema["default"] = v.Interface()
+					}
+				}
+				if in == "path" {
+					if p, ok := pathParams[name]; ok {
+						p["schema"] = schema
+					}
+					continue
+				}
+				params = append(params, map[string]any{
+					"name":     name,
+					"in":       in,
+					"required": hasValidationRule(t, f.jsonField, "required"),
+					"schema":   schema,
+				})
+			}
+		}
+	}
+
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if len(body) > 0 {
+		schema := s.object(body)
+		op["requestBody"] = map[string]any{
+			"content": map[string]any{
+				ContentTypeApplicationJSON: map[string]any{"schema": schema},
+				ContentTypeFormURLEncoded:  map[string]any{"schema": schema},
+			},
+		}
+	}
+
+	ok := map[string]any{"description": "OK"}
+	if rd.resp != nil {
+		ok["content"] = map[string]any{
+			ContentTypeApplicationJSON: map[string]any{"schema": s.schema(rd.resp)},
+		}
+	}
+
+	op["responses"] = map[string]any{
+		"200": ok,
+		"default": map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				ContentTypeApplicationJSON: map[string]any{
+					"schema": map[string]any{"$ref": "#/components/schemas/HaltError"},
+				},
+			},
+		},
+	}
+
+	return op
+}
+
+// buildOpenAPI build an OpenAPI 3.1 document from routes
+func buildOpenAPI(title string, version string, routes []routeDoc) map[string]any {
+	s := &openAPISchemas{
+		names: map[reflect.Type]string{},
+		components: map[string]any{
+			"HaltError": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					HaltExtraKeyMessage: map[string]any{"type": "string"},
+					HaltExtraKeyCode:    map[string]any{"type": "string"},
+				},
+				"required":             []string{HaltExtraKeyMessage},
+				"additionalProperties": true,
+			},
+		},
+	}
+
+	// sort for a stable output
+	routes = append([]routeDoc{}, routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].path == routes[j].path {
+			return routes[i].method < routes[j].method
+		}
+		return routes[i].path < routes[j].path
+	})
+
+	paths := map[string]any{}
+
+	for _, rd := range routes {
+		path := openAPIPath(rd.path)
+
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+
+		methods := []string{rd.method}
+		if rd.method == "" {
+			methods = openAPIAnyMethods
+		}
+
+		for _, method := range methods {
+			key := strings.ToLower(method)
+			if _, ok := item[key]; ok {
+				// method-specific routes take precedence
+				continue
+			}
+			item[key] = s.operation(method, rd)
+		}
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": s.components,
+		},
+	}
+
+	// document registered error codes, as enum of "code" and a catalogue extension
+	if defs := ErrorDefinitions(); len(defs) > 0 {
+		codes := make([]string, 0, len(defs))
+		for _, d := range defs {
+			codes = append(codes, d.code)
+		}
+		schema := s.components["HaltError"].(map[string]any)
+		schema["properties"].(map[string]any)[HaltExtraKeyCode] = map[string]any{"type": "string", "enum": codes}
+		doc["x-error-codes"] = defs
+	}
+
+	return doc
+}
+
+func (a *app[T]) openAPIRoutes() (routes []routeDoc) {
+	routes = append(routes, a.routeDocs...)
+	for _, m := range a.mounts {
+		if src, ok := m.h.(openAPIRouteSource); ok {
+			for _, rd := range src.openAPIRoutes() {
+				rd.path = m.prefix + rd.path
+				routes = append(routes, rd)
+			}
+		}
+	}
+	return
+}
+
+func (a *app[T]) OpenAPI() map[string]any {
+	return buildOpenAPI(a.opts.openAPITitle, a.opts.openAPIVersion, a.openAPIRoutes())
+}
+
+func (a *app[T]) serveOpenAPI(rw http.ResponseWriter) {
+	buf, err := json.MarshalIndent(a.OpenAPI(), "", "  ")
+	if err != nil {
+		respondInternal(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", ContentTypeApplicationJSONUTF8)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(buf)))
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(buf)
+}
@@ -0,0 +1,102 @@
+package summer
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type openAPITestUser struct {
+	ID      string             `json:"id"`
+	Age     int                `json:"age,omitempty"`
+	Friends []*openAPITestUser `json:"friends"`
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	require.Equal(t, "/files/{path}", openAPIPath("/files/{path...}"))
+	require.Equal(t, "/", openAPIPath("/{$}"))
+	require.Equal(t, "getUsersId", openAPIOperationID("GET", "/users/{id}"))
+}
+
+func TestAppOpenAPI(t *testing.T) {
+	type Req struct {
+		ID     string `json:"path_id"`
+		Tenant string `json:"header_x_tenant"`
+		Limit  int    `json:"query_limit,string"`
+		Name   string `json:"name"`
+	}
+
+	sub := Basic()
+	sub.HandleFunc("GET /health", func(c Context) {})
+
+	a := Basic(WithOpenAPIInfo("test", "1.0.0"))
+	HandleTyped(a.Group("/api"), "POST /users/{id}", func(c Context, req Req) (resp openAPITestUser, err error) {
+		return
+	})
+	a.Mount("/sub", sub)
+
+	doc := a.OpenAPI()
+	require.Equal(t, "3.1.0", doc["openapi"])
+
+	paths := doc["paths"].(map[string]any)
+	require.Contains(t, paths, "/sub/health")
+
+	op := paths["/api/users/{id}"].(map[string]any)["post"].(map[string]any)
+	require.Equal(t, "postApiUsersId", op["operationId"])
+	require.Equal(t, []any{
+		map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "x-tenant", "in": "header", "required": false, "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "limit", "in": "query", "required": false, "schema": map[string]any{"type": "string"}},
+	}, op["parameters"])
+	require.Contains(t, op, "requestBody")
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	require.Contains(t, schemas, "HaltError")
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":      map[string]any{"type": "string"},
+			"age":     map[string]any{"type": "integer", "format": "int32"},
+			"friends": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/openAPITestUser"}},
+		},
+	}, schemas["openAPITestUser"])
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/debug/openapi.json", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	var served map[string]any
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &served))
+	require.Equal(t, "test", served["info"].(map[string]any)["title"])
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	type Req struct {
+		ID   int64  `json:"path_id"`
+		Name string `json:"name"`
+	}
+
+	a := Basic()
+	a.HandleFunc("GET /files/{path...}", func(c Context) {})
+	HandleTyped(a, "GET /orgs/{org}/users/{id}", func(c Context, req Req) (resp openAPITestUser, err error) {
+		return
+	})
+
+	paths := a.OpenAPI()["paths"].(map[string]any)
+
+	// untyped route still declares templated parameters
+	op := paths["/files/{path}"].(map[string]any)["get"].(map[string]any)
+	require.Equal(t, []any{
+		map[string]any{"name": "path", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+	}, op["parameters"])
+
+	// path_ field refines schema of its parameter, others are kept as strings
+	op = paths["/orgs/{org}/users/{id}"].(map[string]any)["get"].(map[string]any)
+	require.Equal(t, []any{
+		map[string]any{"name": "org", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "integer", "format": "int64"}},
+		map[string]any{"name": "name", "in": "query", "required": false, "schema": map[string]any{"type": "string"}},
+	}, op["parameters"])
+}
@@ -1,11 +1,81 @@
 package summer
 
+import (
+	"context"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
 type options struct {
 	concurrency      int
 	readinessCascade int64
 	readinessPath    string
 	livenessPath     string
 	metricsPath      string
+	openAPIPath      string
+	openAPITitle     string
+	openAPIVersion   string
+
+	multipartMaxMemory int64
+	multipartMaxDisk   int64
+	maxBodySize        int64
+
+	decoders map[string]decoder
+
+	cookieSecrets [][]byte
+
+	sseHeartbeat time.Duration
+
+	streamFlushInterval time.Duration
+
+	compressors        []compressor
+	compressionMinSize int
+
+	encoders []encoder
+
+	problemDetails bool
+
+	productionMode bool
+	devMode        bool
+
+	messages     map[string]map[string]string
+	localeHeader string
+
+	errorRenderer  func(c Context, err error)
+	errorObservers []func(c Context, err error, recovered bool)
+}
+
+func defaultOptions() options {
+	return options{
+		concurrency:         128,
+		readinessCascade:    5,
+		readinessPath:       DefaultReadinessPath,
+		livenessPath:        DefaultLivenessPath,
+		metricsPath:         DefaultMetricsPath,
+		openAPIPath:         DefaultOpenAPIPath,
+		openAPITitle:        "summer",
+		openAPIVersion:      "0.0.0",
+		multipartMaxMemory:  32 << 20,
+		multipartMaxDisk:    100 << 20,
+		maxBodySize:         10 << 20,
+		decoders:            defaultDecoders(),
+		sseHeartbeat:        15 * time.Second,
+		streamFlushInterval: 100 * time.Millisecond,
+		compressors:         defaultCompressors(),
+		compressionMinSize:  1024,
+		encoders:            defaultEncoders(),
+	}
+}
+
+// optionsFromContext returns options of [App] serving the request, or default options
+func optionsFromContext(ctx context.Context) *options {
+	if opts, ok := ctx.Value(contextKeyOptions).(*options); ok {
+		return opts
+	}
+	opts := defaultOptions()
+	return &opts
 }
 
 // Option a function configuring [App]
@@ -51,3 +121,260 @@ func WithMetricsPath(s string) Option {
 		opts.metricsPath = s
 	}
 }
+
+// WithOpenAPIPath set OpenAPI document path
+//
+// An empty value means disabled
+func WithOpenAPIPath(s string) Option {
+	return func(opts *options) {
+		opts.openAPIPath = s
+	}
+}
+
+// WithOpenAPIInfo set title and version in OpenAPI document
+func WithOpenAPIInfo(title string, version string) Option {
+	return func(opts *options) {
+		opts.openAPITitle = title
+		opts.openAPIVersion = version
+	}
+}
+
+// WithMultipartMaxMemory set maximum bytes of a multipart/form-data request stored in memory,
+// file parts exceeding this are stored in temporary files on disk
+func WithMultipartMaxMemory(n int64) Option {
+	return func(opts *options) {
+		opts.multipartMaxMemory = n
+	}
+}
+
+// WithMultipartMaxDisk set maximum bytes of a multipart/form-data request stored in temporary files,
+// default to 100 MiB, a larger request is rejected with [http.StatusRequestEntityTooLarge]
+//
+// A value <= 0 means unlimited
+func WithMultipartMaxDisk(n int64) Option {
+	return func(opts *options) {
+		opts.multipartMaxDisk = n
+	}
+}
+
+// WithMaxBodySize set maximum bytes of a request body other than multipart/form-data read by [Context.Bind],
+// default to 10 MiB, a larger request is rejected with [http.StatusRequestEntityTooLarge]
+//
+// A value <= 0 means unlimited
+func WithMaxBodySize(n int64) Option {
+	return func(opts *options) {
+		opts.maxBodySize = n
+	}
+}
+
+// WithDecoder register a [DecoderFunc] for a media type like "application/cbor", used by [Context.Bind]
+//
+// Built-in decoders can be overridden, media types with structured syntax suffix like "application/vnd.api+json"
+// fall back to decoder of "application/json" if not registered explicitly, a nil fn removes the media type
+func WithDecoder(mediaType string, fn DecoderFunc) Option {
+	return func(opts *options) {
+		if opts.decoders == nil {
+			opts.decoders = map[string]decoder{}
+		}
+		if fn == nil {
+			delete(opts.decoders, mediaType)
+			return
+		}
+		opts.decoders[mediaType] = decoder{fn: fn}
+	}
+}
+
+// WithCookieSecret set secrets for signed and encrypted cookies, see [CookieWithSigned] and [CookieWithEncrypted]
+//
+// The first secret is used for new cookies, and all secrets are tried for incoming cookies, allowing key rotation
+func WithCookieSecret(secrets ...[]byte) Option {
+	return func(opts *options) {
+		opts.cookieSecrets = secrets
+	}
+}
+
+// WithSSEHeartbeat set interval of heartbeat comments in [Context.SSE] streams, default to 15 seconds
+//
+// A value <= 0 means disabled
+func WithSSEHeartbeat(d time.Duration) Option {
+	return func(opts *options) {
+		opts.sseHeartbeat = d
+	}
+}
+
+// WithStreamFlushInterval set interval of flushing in [Context.Stream] and [Context.NDJSON], written data
+// reaches client within an interval even if handler stalls, default to 100 milliseconds
+//
+// A value <= 0 means flushing on every write
+func WithStreamFlushInterval(d time.Duration) Option {
+	return func(opts *options) {
+		opts.streamFlushInterval = d
+	}
+}
+
+// WithCompressionMinSize set minimum size of a response body to be compressed, default to 1024 bytes,
+// streaming responses are always compressed if acceptable
+//
+// A value < 0 means compression disabled
+func WithCompressionMinSize(n int) Option {
+	return func(opts *options) {
+		opts.compressionMinSize = n
+	}
+}
+
+// WithCompressor register a [CompressorFunc] for a content coding like "br" or "zstd", negotiated with
+// Accept-Encoding header, built-in "gzip" and "deflate" can be overridden
+//
+// Registered compressors are preferred over built-in ones if client accepts them equally, a nil fn removes the
+// content coding
+func WithCompressor(coding string, fn CompressorFunc) Option {
+	return func(opts *options) {
+		var compressors []compressor
+		if fn != nil {
+			compressors = append(compressors, compressor{coding: coding, fn: fn})
+		}
+		for _, item := range opts.compressors {
+			if item.coding != coding {
+				compressors = append(compressors, item)
+			}
+		}
+		opts.compressors = compressors
+	}
+}
+
+// WithEncoder register an [EncoderFunc] for a content type like "application/cbor" or "text/html; charset=utf-8",
+// negotiated with Accept header by [Context.Render]
+//
+// Built-in encoders for JSON, XML, msgpack, YAML and HTML can be overridden, a new encoder is preferred less than
+// existing ones if client accepts them equally, a nil fn removes the media type
+func WithEncoder(contentType string, fn EncoderFunc) Option {
+	return func(opts *options) {
+		e := newEncoder(contentType, fn)
+		var encoders []encoder
+		var found bool
+		for _, item := range opts.encoders {
+			if item.mediaType == e.mediaType {
+				found = true
+				if fn != nil {
+					encoders = append(encoders, e)
+				}
+				continue
+			}
+			encoders = append(encoders, item)
+		}
+		if !found && fn != nil {
+			encoders = append(encoders, e)
+		}
+		opts.encoders = encoders
+	}
+}
+
+// WithProblemDetails render error responses as RFC 9457 problem details, with content type
+// "application/problem+json", see [ProblemFromError], [HaltWithType] and [HaltWithTitle]
+func WithProblemDetails() Option {
+	return func(opts *options) {
+		opts.problemDetails = true
+	}
+}
+
+// WithProductionMode hide messages of internal errors in responses, a 5xx error not created by [Halt] or
+// [NewHaltError], like a panic or an error returned by typed handler, is responded with a generic message
+// and key "trace_id" from [TraceIDFromContext]
+//
+// Error observers still receive the full error, while [WithErrorRenderer] receives the generic one
+func WithProductionMode(enabled bool) Option {
+	return func(opts *options) {
+		opts.productionMode = enabled
+	}
+}
+
+// WithDevMode expose details of errors in responses for local debugging, "origin" as file:line where [Halt] was
+// called, and "stack" as stack trace of a recovered panic, an HTML debug page is rendered for clients preferring
+// HTML, like browsers
+//
+// It is ignored in production mode, see [WithProductionMode]
+func WithDevMode(enabled bool) Option {
+	return func(opts *options) {
+		opts.devMode = enabled
+	}
+}
+
+// WithModeFromEnv set mode from environment variable [EnvKeyMode], "production" or "prod" enables
+// [WithProductionMode], "development" or "dev" enables [WithDevMode], other values leave options unchanged
+func WithModeFromEnv() Option {
+	return func(opts *options) {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvKeyMode))) {
+		case "production", "prod":
+			opts.productionMode = true
+		case "development", "dev":
+			opts.devMode = true
+		}
+	}
+}
+
+// WithMessages register a message catalog of locale like "zh-CN", keyed by message ID or error code,
+// values are templates formatted like [fmt.Sprintf], see [HaltWithMessageID], [DefineError] and [Localize]
+//
+// Messages of error responses are localized by the default renderer, with locale picked from header set by
+// [WithLocaleHeader] or Accept-Language header, error observers still receive the original message.
+// Messages of the same locale are merged
+func WithMessages(locale string, messages map[string]string) Option {
+	return func(opts *options) {
+		if opts.messages == nil {
+			opts.messages = map[string]map[string]string{}
+		}
+		locale = normalizeLocale(locale)
+		if opts.messages[locale] == nil {
+			opts.messages[locale] = map[string]string{}
+		}
+		for k, v := range messages {
+			opts.messages[locale][k] = v
+		}
+	}
+}
+
+// WithMessagesFS register message catalogs from JSON files in fsys matching pattern like "locales/*.json",
+// locale is the file name without extension, see [WithMessages]
+//
+// Use [os.DirFS] for files on disk, or an [embed.FS]. It panics if files can not be loaded
+func WithMessagesFS(fsys fs.FS, pattern string) Option {
+	catalogs, err := loadMessagesFS(fsys, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return func(opts *options) {
+		for locale, messages := range catalogs {
+			WithMessages(locale, messages)(opts)
+		}
+	}
+}
+
+// WithLocaleHeader set a header like "X-Locale" picking locale of messages, preferred over Accept-Language header
+func WithLocaleHeader(name string) Option {
+	return func(opts *options) {
+		opts.localeHeader = name
+	}
+}
+
+// WithErrorRenderer set a function rendering error responses in [Context.Perform], instead of the default JSON body
+//
+// Status code is already set with [StatusCodeFromError] before fn is invoked, and can be changed by fn.
+// The default renderer is used if fn panics, and the panic is reported to [WithErrorObserver] as a [*PanicError]
+func WithErrorRenderer(fn func(c Context, err error)) Option {
+	return func(opts *options) {
+		opts.errorRenderer = fn
+	}
+}
+
+// WithErrorObserver add a function invoked for every error handled by [Context.Perform], including errors after
+// a streaming response started, for logging and reporting
+//
+// recovered is true for a real panic, in which case err is a [*PanicError] with stack trace, and false for
+// [Halt] and errors returned by typed handlers
+//
+// A panic of an observer is reported to the other observers as a [*PanicError]
+func WithErrorObserver(fn func(c Context, err error, recovered bool)) Option {
+	return func(opts *options) {
+		opts.errorObservers = append(opts.errorObservers, fn)
+	}
+}
@@ -3,6 +3,7 @@ package summer
 import (
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -25,4 +26,111 @@ func TestOptions(t *testing.T) {
 	opts = options{}
 	WithMetricsPath("/aaa")(&opts)
 	require.Equal(t, "/aaa", opts.metricsPath)
+
+	opts = options{}
+	WithOpenAPIPath("/aaa")(&opts)
+	require.Equal(t, "/aaa", opts.openAPIPath)
+
+	opts = options{}
+	WithOpenAPIInfo("aaa", "1.0.0")(&opts)
+	require.Equal(t, "aaa", opts.openAPITitle)
+	require.Equal(t, "1.0.0", opts.openAPIVersion)
+
+	opts = options{}
+	WithMultipartMaxMemory(2)(&opts)
+	require.Equal(t, int64(2), opts.multipartMaxMemory)
+
+	// multipart bodies are limited by default, like other bodies
+	opts = defaultOptions()
+	require.Equal(t, int64(100<<20), opts.multipartMaxDisk)
+	require.Equal(t, int64(10<<20), opts.maxBodySize)
+
+	opts = options{}
+	WithMultipartMaxDisk(2)(&opts)
+	require.Equal(t, int64(2), opts.multipartMaxDisk)
+
+	opts = options{}
+	WithMaxBodySize(3)(&opts)
+	require.Equal(t, int64(3), opts.maxBodySize)
+
+	opts = options{}
+	WithDecoder("application/x-aaa", DecodeText)(&opts)
+	require.NotNil(t, opts.decoders["application/x-aaa"].fn)
+
+	opts = options{}
+	WithCookieSecret([]byte("a"), []byte("b"))(&opts)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, opts.cookieSecrets)
+
+	opts = options{}
+	WithSSEHeartbeat(time.Second)(&opts)
+	require.Equal(t, time.Second, opts.sseHeartbeat)
+
+	opts = options{}
+	WithStreamFlushInterval(time.Second)(&opts)
+	require.Equal(t, time.Second, opts.streamFlushInterval)
+
+	opts = options{}
+	WithCompressionMinSize(2)(&opts)
+	require.Equal(t, 2, opts.compressionMinSize)
+
+	opts = defaultOptions()
+	WithCompressor("br", CompressGzip)(&opts)
+	WithCompressor("deflate", nil)(&opts)
+	require.Len(t, opts.compressors, 2)
+	require.Equal(t, "br", opts.compressors[0].coding)
+	require.Equal(t, "gzip", opts.compressors[1].coding)
+
+	opts = defaultOptions()
+	WithEncoder("application/json", EncodeYAML)(&opts)
+	WithEncoder("text/html", nil)(&opts)
+	WithEncoder("text/csv", EncodeJSON)(&opts)
+	require.Equal(t, "application/json", opts.encoders[0].contentType)
+	require.Equal(t, "text/csv", opts.encoders[len(opts.encoders)-1].mediaType)
+	for _, e := range opts.encoders {
+		require.NotEqual(t, ContentTypeTextHTML, e.mediaType)
+	}
+
+	opts = options{}
+	WithProblemDetails()(&opts)
+	require.True(t, opts.problemDetails)
+
+	opts = options{}
+	WithProductionMode(true)(&opts)
+	require.True(t, opts.productionMode)
+
+	opts = options{}
+	t.Setenv(EnvKeyMode, "development")
+	WithModeFromEnv()(&opts)
+	require.False(t, opts.productionMode)
+	t.Setenv(EnvKeyMode, " Production ")
+	WithModeFromEnv()(&opts)
+	require.True(t, opts.productionMode)
+
+	opts = options{}
+	WithDevMode(true)(&opts)
+	require.True(t, opts.devMode)
+
+	opts = options{}
+	t.Setenv(EnvKeyMode, "dev")
+	WithModeFromEnv()(&opts)
+	require.True(t, opts.devMode)
+	require.False(t, opts.productionMode)
+
+	opts = options{}
+	WithMessages("zh_CN", map[string]string{"a": "1"})(&opts)
+	WithMessages("zh-cn", map[string]string{"b": "2"})(&opts)
+	require.Equal(t, map[string]map[string]string{"zh-cn": {"a": "1", "b": "2"}}, opts.messages)
+
+	opts = options{}
+	WithLocaleHeader("X-Locale")(&opts)
+	require.Equal(t, "X-Locale", opts.localeHeader)
+
+	opts = options{}
+	WithErrorRenderer(func(c Context, err error) {})(&opts)
+	require.NotNil(t, opts.errorRenderer)
+
+	opts = options{}
+	WithErrorObserver(func(c Context, err error, recovered bool) {})(&opts)
+	WithErrorObserver(func(c Context, err error, recovered bool) {})(&opts)
+	require.Len(t, opts.errorObservers, 2)
 }
@@ -0,0 +1,71 @@
+package summer
+
+import "net/http"
+
+const (
+	ProblemKeyType     = "type"
+	ProblemKeyTitle    = "title"
+	ProblemKeyStatus   = "status"
+	ProblemKeyDetail   = "detail"
+	ProblemKeyInstance = "instance"
+)
+
+// ProblemFromError create RFC 9457 problem details from previous created [HaltError]
+//
+// Message becomes "detail", extras become extension members, "type" and "title" come from [HaltWithType] and
+// [HaltWithTitle], defaulting to "about:blank" and the status text
+func ProblemFromError(err error, instance string) (m map[string]any) {
+	status := StatusCodeFromError(err)
+
+	m = BodyFromError(err)
+	if m == nil {
+		m = map[string]any{}
+	}
+	if detail, ok := m[HaltExtraKeyMessage]; ok {
+		delete(m, HaltExtraKeyMessage)
+		m[ProblemKeyDetail] = detail
+	}
+
+	m[ProblemKeyType] = "about:blank"
+	m[ProblemKeyTitle] = http.StatusText(status)
+
+	// the outermost one wins
+	if s := problemTypeOf(err); s != "" {
+		m[ProblemKeyType] = s
+	}
+	if s := problemTitleOf(err); s != "" {
+		m[ProblemKeyTitle] = s
+	}
+
+	m[ProblemKeyStatus] = status
+	if instance != "" {
+		m[ProblemKeyInstance] = instance
+	}
+	return
+}
+
+// problemTypeOf returns the outermost problem type in an error tree, in the order of [StatusCodeFromError] precedence
+func problemTypeOf(err error) string {
+	if ep, ok := err.(withProblem); ok && ep.ProblemType() != "" {
+		return ep.ProblemType()
+	}
+	for _, e := range unwrapError(err) {
+		if s := problemTypeOf(e); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// problemTitleOf returns the outermost problem title in an error tree, in the order of [StatusCodeFromError] precedence
+func problemTitleOf(err error) string {
+	if ep, ok := err.(withProblem); ok && ep.ProblemTitle() != "" {
+		return ep.ProblemTitle()
+	}
+	for _, e := range unwrapError(err) {
+		if s := problemTitleOf(e); s != "" {
+			return s
+		}
+	}
+	return ""
+}
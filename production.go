@@ -0,0 +1,63 @@
+package summer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"strings"
+)
+
+const (
+	// EnvKeyMode environment variable read by [WithModeFromEnv]
+	EnvKeyMode = "SUMMER_ENV"
+)
+
+// TraceIDFromContext returns trace ID of the OpenTelemetry span in ctx, or the correlation ID generated for an error
+// in production mode if there is no span, empty if neither exists
+//
+// It is the "trace_id" in error responses of production mode, use it in [WithErrorObserver] to correlate logs
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.TraceID().IsValid() {
+		return sc.TraceID().String()
+	}
+	id, _ := ctx.Value(contextKeyTraceID).(string)
+	return id
+}
+
+// newCorrelationID create a random ID in the same format of an OpenTelemetry trace ID
+func newCorrelationID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// ensureTraceID make sure [TraceIDFromContext] returns a non-empty ID for the request
+func (c *basicContext) ensureTraceID() {
+	if TraceIDFromContext(c) != "" {
+		return
+	}
+	id := newCorrelationID()
+	c.Inject(func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, contextKeyTraceID, id)
+	})
+}
+
+// concealError replace a 5xx error not halted explicitly with a generic one carrying trace ID in production mode,
+// since message of an internal error may contain SQL, hostnames or file paths
+func (c *basicContext) concealError(err error) error {
+	if !c.opts.productionMode || haltedExplicitly(err) {
+		return err
+	}
+	code := StatusCodeFromError(err)
+	if code < http.StatusInternalServerError {
+		return err
+	}
+	return NewHaltError(
+		errors.New(strings.ToLower(http.StatusText(code))),
+		HaltWithStatusCode(code),
+		HaltWithExtra(HaltExtraKeyTraceID, TraceIDFromContext(c)),
+	)
+}
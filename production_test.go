@@ -0,0 +1,110 @@
+package summer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceIDFromContext(t *testing.T) {
+	require.Equal(t, "", TraceIDFromContext(context.Background()))
+
+	ctx := context.WithValue(context.Background(), contextKeyTraceID, "correlation")
+	require.Equal(t, "correlation", TraceIDFromContext(ctx))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:  trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	})
+	ctx = trace.ContextWithSpanContext(ctx, sc)
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", TraceIDFromContext(ctx))
+}
+
+func TestProductionMode(t *testing.T) {
+	var observed []string
+
+	a := Basic(
+		WithProductionMode(true),
+		WithErrorObserver(func(c Context, err error, recovered bool) {
+			observed = append(observed, err.Error()+" "+TraceIDFromContext(c))
+		}),
+	)
+	a.HandleFunc("/panic", func(c Context) {
+		panic(errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	})
+	a.HandleFunc("/halt", func(c Context) {
+		HaltString("upstream unavailable", HaltWithStatusCode(http.StatusServiceUnavailable))
+	})
+	a.HandleFunc("/bad", func(c Context) {
+		c.Bind(&struct {
+			Age int `json:"query_age"`
+		}{})
+	})
+	a.HandleFunc("/stream", func(c Context) {
+		c.Stream(ContentTypeTextPlainUTF8, func(w io.Writer) error {
+			_, _ = w.Write([]byte("partial"))
+			return errors.New("select * from secrets")
+		})
+	})
+	HandleTyped(a, "/typed", func(c Context, req struct{}) (any, error) {
+		return nil, errors.New("open /etc/secret: permission denied")
+	})
+	HandleTyped(a, "/typed-halt", func(c Context, req struct{}) (any, error) {
+		return nil, NewHaltError(errors.New("quota exceeded"), HaltWithStatusCode(http.StatusInsufficientStorage))
+	})
+
+	do := func(path string) (*httptest.ResponseRecorder, map[string]any) {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		a.ServeHTTP(rw, req)
+		var m map[string]any
+		_ = json.Unmarshal(rw.Body.Bytes(), &m)
+		return rw, m
+	}
+
+	rw, m := do("/panic")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "internal server error", m["message"])
+	require.Len(t, m["trace_id"], 32)
+	require.Equal(t, "dial tcp 10.0.0.1:5432: connection refused "+m["trace_id"].(string), observed[0])
+
+	rw, m = do("/typed")
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, "internal server error", m["message"])
+	require.Len(t, m["trace_id"], 32)
+	require.Equal(t, "open /etc/secret: permission denied "+m["trace_id"].(string), observed[1])
+
+	rw, m = do("/halt")
+	require.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	require.Equal(t, map[string]any{"message": "upstream unavailable"}, m)
+
+	rw, m = do("/typed-halt")
+	require.Equal(t, http.StatusInsufficientStorage, rw.Code)
+	require.Equal(t, map[string]any{"message": "quota exceeded"}, m)
+
+	rw, m = do("/bad?age=x")
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.NotContains(t, m, "trace_id")
+
+	rw, _ = do("/stream")
+	require.Equal(t, "partial", rw.Body.String())
+	require.Contains(t, rw.Result().Trailer.Get(StreamErrorTrailer), "internal server error (trace_id: ")
+	require.Contains(t, observed[len(observed)-1], "select * from secrets")
+}
+
+func TestProductionModeDisabled(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/panic", func(c Context) {
+		panic(errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, `{"message":"dial tcp 10.0.0.1:5432: connection refused"}`, rw.Body.String())
+}
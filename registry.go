@@ -27,7 +27,7 @@ type Registry interface {
 	// Inject execute all inject funcs with [Context]
 	Inject(c Context)
 
-	// Shutdown shutdown all registered components
+	// Shutdown shutdown all started components, in reverse order of startup
 	Shutdown(ctx context.Context) (err error)
 }
 
@@ -114,8 +114,10 @@ func (a *registry) Startup(ctx context.Context) (err error) {
 		if err == nil {
 			return
 		}
-		for _, item := range a.init {
-			_ = item.shutdown(ctx)
+		for i := len(a.init) - 1; i >= 0; i-- {
+			if item := a.init[i]; item.shutdown != nil {
+				_ = item.shutdown(ctx)
+			}
 		}
 		a.init = nil
 	}()
@@ -164,14 +166,12 @@ func (a *registry) Shutdown(ctx context.Context) (err error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	for _, item := range a.init {
-		if err1 := item.shutdown(ctx); err1 != nil {
-			if err == nil {
-				err = err1
-			} else {
-				err = errors.New(err.Error() + "; " + err1.Error())
-			}
+	for i := len(a.init) - 1; i >= 0; i-- {
+		item := a.init[i]
+		if item.shutdown == nil {
+			continue
 		}
+		err = combineErrors(err, item.shutdown(ctx))
 	}
 
 	a.init = nil
@@ -179,6 +179,17 @@ func (a *registry) Shutdown(ctx context.Context) (err error) {
 	return
 }
 
+// combineErrors combine messages of two errors, nil errors are skipped
+func combineErrors(err error, err1 error) error {
+	if err == nil {
+		return err1
+	}
+	if err1 == nil {
+		return err
+	}
+	return errors.New(err.Error() + "; " + err1.Error())
+}
+
 func NewRegistry() Registry {
 	return &registry{mu: &sync.Mutex{}}
 }
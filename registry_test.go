@@ -124,3 +124,22 @@ func TestRegistryFailed(t *testing.T) {
 	require.False(t, t2b)
 	require.False(t, t2c)
 }
+
+func TestRegistryShutdownOrder(t *testing.T) {
+	a := NewRegistry()
+	var order []string
+	for _, name := range []string{"test-1", "test-2", "test-3"} {
+		name := name
+		a.Component(name).Shutdown(func(ctx context.Context) (err error) {
+			order = append(order, name)
+			return errors.New(name + " failed")
+		})
+	}
+	a.Component("test-4")
+
+	require.NoError(t, a.Startup(context.Background()))
+
+	err := a.Shutdown(context.Background())
+	require.Equal(t, []string{"test-3", "test-2", "test-1"}, order)
+	require.EqualError(t, err, "test-3 failed; test-2 failed; test-1 failed")
+}
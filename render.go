@@ -0,0 +1,176 @@
+package summer
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type encoder struct {
+	mediaType   string
+	contentType string
+	fn          EncoderFunc
+}
+
+// newEncoder create an encoder with content type like "application/json; charset=utf-8"
+func newEncoder(contentType string, fn EncoderFunc) encoder {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		panic("invalid content type of encoder: " + contentType)
+	}
+	return encoder{mediaType: mediaType, contentType: contentType, fn: fn}
+}
+
+func defaultEncoders() []encoder {
+	return []encoder{
+		newEncoder(ContentTypeApplicationJSONUTF8, EncodeJSON),
+		newEncoder(ContentTypeApplicationXML+"; charset=utf-8", EncodeXML),
+		newEncoder(ContentTypeTextXML+"; charset=utf-8", EncodeXML),
+		newEncoder(ContentTypeApplicationMsgpack, EncodeMsgpack),
+		newEncoder(ContentTypeApplicationMsgpackLegacy, EncodeMsgpack),
+		newEncoder(ContentTypeApplicationYAML+"; charset=utf-8", EncodeYAML),
+		newEncoder(ContentTypeTextHTML+"; charset=utf-8", EncodeHTML),
+	}
+}
+
+type acceptRange struct {
+	typ string
+	sub string
+	q   float64
+}
+
+// parseAccept parse media ranges of Accept header, parameters other than q are ignored
+func parseAccept(header string) (ranges []acceptRange) {
+	for _, item := range strings.Split(header, ",") {
+		mediaType, params, _ := strings.Cut(item, ";")
+		typ, sub, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		if !ok {
+			continue
+		}
+		r := acceptRange{typ: strings.TrimSpace(typ), sub: strings.TrimSpace(sub), q: 1}
+		for _, param := range strings.Split(params, ";") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.TrimSpace(k) == "q" {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					r.q = f
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return
+}
+
+// acceptQ returns quality value of media type from the most specific matching range, 0 if not acceptable
+func acceptQ(ranges []acceptRange, mediaType string) (q float64) {
+	typ, sub, _ := strings.Cut(mediaType, "/")
+	best := -1
+	for _, r := range ranges {
+		var specificity int
+		switch {
+		case r.typ == typ && r.sub == sub:
+			specificity = 2
+		case r.typ == typ && r.sub == "*":
+			specificity = 1
+		case r.typ == "*" && r.sub == "*":
+			specificity = 0
+		default:
+			continue
+		}
+		if specificity > best {
+			best, q = specificity, r.q
+		}
+	}
+	return
+}
+
+// negotiateEncoder pick the encoder with the highest quality value from Accept header, ties are broken by order
+// of encoders, and the first encoder is picked if Accept header is missing
+func negotiateEncoder(encoders []encoder, header string) (e encoder, ok bool) {
+	if len(encoders) == 0 {
+		return
+	}
+	if strings.TrimSpace(header) == "" {
+		return encoders[0], true
+	}
+	ranges := parseAccept(header)
+	var best float64
+	for _, item := range encoders {
+		if q := acceptQ(ranges, item.mediaType); q > best {
+			e, ok, best = item, true, q
+		}
+	}
+	return
+}
+
+func (c *basicContext) Render(v any) {
+	addVary(c.rw.Header(), "Accept")
+
+	e, ok := negotiateEncoder(c.opts.encoders, c.req.Header.Get("Accept"))
+	if !ok {
+		HaltString("not acceptable", HaltWithStatusCode(http.StatusNotAcceptable))
+	}
+
+	buf, err := e.fn(v)
+	if err != nil {
+		raise(err)
+	}
+	c.Body(e.contentType, buf)
+}
+
+// renderError render an error response with the same negotiation as [Context.Render], falls back to the first
+// encoder, or JSON, since an error response must be sent anyway
+//
+// In dev mode, origin and stack trace are added, and a debug page is rendered for clients preferring HTML
+func (c *basicContext) renderError(err error) {
+	c.Code(StatusCodeFromError(err))
+
+	var (
+		m           map[string]any
+		contentType string
+	)
+	message, localized := c.localizeError(err)
+	if c.opts.problemDetails {
+		m = ProblemFromError(err, c.req.URL.Path)
+		if localized {
+			m[ProblemKeyDetail] = message
+		}
+		contentType = ContentTypeApplicationProblemJSON
+	} else {
+		m = BodyFromError(err)
+		if localized {
+			m[HaltExtraKeyMessage] = message
+		}
+		contentType = ContentTypeApplicationJSONUTF8
+	}
+
+	if c.devMode() {
+		addDebugExtras(m, err)
+		if prefersHTML(c.req.Header.Get("Accept")) {
+			addVary(c.rw.Header(), "Accept")
+			c.renderDebugPage(m)
+			return
+		}
+	}
+
+	addVary(c.rw.Header(), "Accept")
+
+	e, ok := negotiateEncoder(c.opts.encoders, c.req.Header.Get("Accept"))
+	if !ok && len(c.opts.encoders) > 0 {
+		e, ok = c.opts.encoders[0], true
+	}
+	if ok {
+		if buf, err := e.fn(m); err == nil {
+			if e.mediaType == ContentTypeApplicationJSON {
+				c.Body(contentType, buf)
+			} else {
+				c.Body(e.contentType, buf)
+			}
+			return
+		}
+	}
+
+	buf, _ := json.Marshal(m)
+	c.Body(contentType, buf)
+}
@@ -0,0 +1,103 @@
+package summer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateEncoder(t *testing.T) {
+	encoders := defaultEncoders()
+
+	e, ok := negotiateEncoder(encoders, "")
+	require.True(t, ok)
+	require.Equal(t, ContentTypeApplicationJSON, e.mediaType)
+
+	e, ok = negotiateEncoder(encoders, "*/*")
+	require.True(t, ok)
+	require.Equal(t, ContentTypeApplicationJSON, e.mediaType)
+
+	// browser
+	e, ok = negotiateEncoder(encoders, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	require.True(t, ok)
+	require.Equal(t, ContentTypeTextHTML, e.mediaType)
+
+	e, ok = negotiateEncoder(encoders, "application/json;q=0.5, application/*;q=0.8")
+	require.True(t, ok)
+	require.Equal(t, ContentTypeApplicationXML, e.mediaType)
+
+	e, ok = negotiateEncoder(encoders, "application/yaml, */*;q=0")
+	require.True(t, ok)
+	require.Equal(t, ContentTypeApplicationYAML, e.mediaType)
+
+	_, ok = negotiateEncoder(encoders, "image/png")
+	require.False(t, ok)
+
+	_, ok = negotiateEncoder(encoders, "application/json;q=0")
+	require.False(t, ok)
+}
+
+func TestContextRender(t *testing.T) {
+	a := Basic(WithEncoder("text/csv", func(v any) ([]byte, error) {
+		return []byte("a,b"), nil
+	}))
+	a.HandleFunc("/render", func(c Context) {
+		c.Render(map[string]string{"hello": "world"})
+	})
+	a.HandleFunc("/fail", func(c Context) {
+		Halt(errors.New("bad"), HaltWithBadRequest())
+	})
+
+	do := func(path string, accept string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := do("/render", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, "Accept", rw.Header().Get("Vary"))
+	require.Equal(t, `{"hello":"world"}`, rw.Body.String())
+
+	rw = do("/render", "application/xml")
+	require.Equal(t, "application/xml; charset=utf-8", rw.Header().Get("Content-Type"))
+	require.Contains(t, rw.Body.String(), "<response><hello>world</hello></response>")
+
+	rw = do("/render", "text/csv")
+	require.Equal(t, "text/csv", rw.Header().Get("Content-Type"))
+	require.Equal(t, "a,b", rw.Body.String())
+
+	rw = do("/render", "image/png")
+	require.Equal(t, http.StatusNotAcceptable, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, `{"message":"not acceptable"}`, rw.Body.String())
+
+	// error body negotiated the same way
+	rw = do("/fail", "application/yaml")
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Equal(t, "application/yaml; charset=utf-8", rw.Header().Get("Content-Type"))
+	require.Equal(t, "message: bad\n", rw.Body.String())
+}
+
+func TestContextProblemDetails(t *testing.T) {
+	a := Basic(WithProblemDetails())
+	a.HandleFunc("/fail", func(c Context) {
+		HaltString("bad input", HaltWithBadRequest(), HaltWithTitle("Invalid Input"), HaltWithExtra("field", "name"))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/fail?a=b", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Equal(t, ContentTypeApplicationProblemJSON, rw.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"type":"about:blank","title":"Invalid Input","status":400,"detail":"bad input","instance":"/fail","field":"name"}`, rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil)
+	req.Header.Set("Accept", "application/yaml")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	require.Equal(t, `detail: "not found"`+"\n"+`instance: "/missing"`+"\n"+"status: 404\n"+`title: "Not Found"`+"\n"+`type: "about:blank"`+"\n", rw.Body.String())
+}
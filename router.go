@@ -0,0 +1,323 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// pathParamsFromContext returns path parameters captured by router
+func pathParamsFromContext(ctx context.Context) map[string]string {
+	m, _ := ctx.Value(contextKeyPathParams).(map[string]string)
+	return m
+}
+
+type routeSegmentKind int
+
+const (
+	routeSegmentStatic routeSegmentKind = iota
+	routeSegmentParam
+	routeSegmentWildcard
+)
+
+type routeSegment struct {
+	kind  routeSegmentKind
+	value string
+}
+
+// parseRoutePattern parse a route pattern like "GET /users/{id}"
+//
+// A trailing slash matches the whole subtree, just like [http.ServeMux] does, "{name...}" captures the rest of the path,
+// and "{$}" matches only the trailing slash itself.
+func parseRoutePattern(pattern string) (method string, path string, segs []routeSegment, err error) {
+	path = strings.TrimSpace(pattern)
+	if i := strings.IndexAny(path, " \t"); i >= 0 {
+		method, path = path[:i], strings.TrimSpace(path[i+1:])
+		for _, c := range method {
+			if c < 'A' || c > 'Z' {
+				err = errors.New("invalid method in route pattern: " + pattern)
+				return
+			}
+		}
+	}
+	if !strings.HasPrefix(path, "/") {
+		err = errors.New("route pattern must start with '/': " + pattern)
+		return
+	}
+
+	names := map[string]bool{}
+
+	items := strings.Split(path[1:], "/")
+	for i, item := range items {
+		last := i == len(items)-1
+
+		if item == "" {
+			if !last {
+				err = errors.New("empty segment in route pattern: " + pattern)
+				return
+			}
+			segs = append(segs, routeSegment{kind: routeSegmentWildcard})
+			continue
+		}
+
+		if !strings.HasPrefix(item, "{") || !strings.HasSuffix(item, "}") {
+			if strings.ContainsAny(item, "{}") {
+				err = errors.New("invalid segment in route pattern: " + pattern)
+				return
+			}
+			segs = append(segs, routeSegment{kind: routeSegmentStatic, value: item})
+			continue
+		}
+
+		name := item[1 : len(item)-1]
+
+		if name == "$" {
+			if !last {
+				err = errors.New("'{$}' must be the last segment in route pattern: " + pattern)
+				return
+			}
+			segs = append(segs, routeSegment{kind: routeSegmentStatic})
+			continue
+		}
+
+		kind := routeSegmentParam
+		if strings.HasSuffix(name, "...") {
+			if !last {
+				err = errors.New("wildcard must be the last segment in route pattern: " + pattern)
+				return
+			}
+			kind = routeSegmentWildcard
+			name = strings.TrimSuffix(name, "...")
+		}
+
+		if name == "" || strings.ContainsAny(name, "{}/ ") {
+			err = errors.New("invalid parameter name in route pattern: " + pattern)
+			return
+		}
+		if names[name] {
+			err = errors.New("duplicated parameter name in route pattern: " + pattern)
+			return
+		}
+		names[name] = true
+
+		segs = append(segs, routeSegment{kind: kind, value: name})
+	}
+	return
+}
+
+type route struct {
+	method  string
+	pattern string
+	path    string
+	params  []string
+	handler http.Handler
+}
+
+type routeNode struct {
+	static   map[string]*routeNode
+	param    *routeNode
+	wildcard *routeNode
+	routes   map[string]*route
+}
+
+func (n *routeNode) pick(method string) *route {
+	if r := n.routes[method]; r != nil {
+		return r
+	}
+	if method == http.MethodHead {
+		if r := n.routes[http.MethodGet]; r != nil {
+			return r
+		}
+	}
+	return n.routes[""]
+}
+
+// lookup walks all nodes matching segs, in order of static, param and wildcard, until fn returns true
+func (n *routeNode) lookup(segs []string, vals []string, fn func(n *routeNode, vals []string) bool) bool {
+	if len(segs) == 0 {
+		return fn(n, vals)
+	}
+	if c := n.static[segs[0]]; c != nil {
+		if c.lookup(segs[1:], vals, fn) {
+			return true
+		}
+	}
+	if n.param != nil && segs[0] != "" {
+		if n.param.lookup(segs[1:], append(vals, segs[0]), fn) {
+			return true
+		}
+	}
+	if n.wildcard != nil {
+		if fn(n.wildcard, append(vals, strings.Join(segs, "/"))) {
+			return true
+		}
+	}
+	return false
+}
+
+type router struct {
+	mu   sync.RWMutex
+	root *routeNode
+
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+func newRouter() *router {
+	return &router{
+		root:     &routeNode{},
+		notFound: http.NotFoundHandler(),
+		methodNotAllowed: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			respondInternal(rw, "method not allowed", http.StatusMethodNotAllowed)
+		}),
+	}
+}
+
+// Handle register a handler with pattern, panics on invalid or conflicting pattern
+func (r *router) Handle(pattern string, h http.Handler) {
+	method, path, segs, err := parseRoutePattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rt := &route{
+		method:  method,
+		pattern: pattern,
+		path:    path,
+		handler: h,
+	}
+
+	n := r.root
+	for _, seg := range segs {
+		switch seg.kind {
+		case routeSegmentStatic:
+			if n.static == nil {
+				n.static = map[string]*routeNode{}
+			}
+			if n.static[seg.value] == nil {
+				n.static[seg.value] = &routeNode{}
+			}
+			n = n.static[seg.value]
+		case routeSegmentParam:
+			if n.param == nil {
+				n.param = &routeNode{}
+			}
+			n = n.param
+			rt.params = append(rt.params, seg.value)
+		case routeSegmentWildcard:
+			if n.wildcard == nil {
+				n.wildcard = &routeNode{}
+			}
+			n = n.wildcard
+			rt.params = append(rt.params, seg.value)
+		}
+	}
+
+	if n.routes == nil {
+		n.routes = map[string]*route{}
+	}
+	if prev := n.routes[method]; prev != nil {
+		panic("route pattern " + pattern + " conflicts with " + prev.pattern)
+	}
+	n.routes[method] = rt
+}
+
+// match find the route for method and path, with captured values, or methods allowed on path if nothing matched
+func (r *router) match(method string, path string) (rt *route, vals []string, allowed []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	segs := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	r.root.lookup(segs, nil, func(n *routeNode, v []string) bool {
+		if rt = n.pick(method); rt != nil {
+			vals = v
+			return true
+		}
+		return false
+	})
+
+	if rt != nil {
+		return
+	}
+
+	set := map[string]bool{}
+	r.root.lookup(segs, nil, func(n *routeNode, _ []string) bool {
+		for m := range n.routes {
+			set[m] = true
+			if m == http.MethodGet {
+				set[http.MethodHead] = true
+			}
+		}
+		return false
+	})
+	for m := range set {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return
+}
+
+// cleanPath returns the canonical path like [http.ServeMux] does, eliminating "." and ".." elements and repeated
+// slashes, a trailing slash is kept
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	np := path.Clean(p)
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+func (r *router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// redirect to the canonical path before matching, like [http.ServeMux]
+	if req.Method != http.MethodConnect {
+		if p := cleanPath(req.URL.Path); p != req.URL.Path {
+			u := *req.URL
+			u.Path, u.RawPath = p, ""
+			http.Redirect(rw, req, u.String(), http.StatusMovedPermanently)
+			return
+		}
+	}
+
+	rt, vals, allowed := r.match(req.Method, req.URL.Path)
+
+	if rt == nil {
+		if len(allowed) > 0 {
+			rw.Header().Set("Allow", strings.Join(allowed, ", "))
+			r.methodNotAllowed.ServeHTTP(rw, req)
+		} else {
+			r.notFound.ServeHTTP(rw, req)
+		}
+		return
+	}
+
+	if len(rt.params) > 0 {
+		m := map[string]string{}
+		// keep parameters captured by parent router, see [Group.Mount]
+		for k, v := range pathParamsFromContext(req.Context()) {
+			m[k] = v
+		}
+		for i, name := range rt.params {
+			if name == "" {
+				continue
+			}
+			m[name] = vals[i]
+		}
+		req = req.WithContext(context.WithValue(req.Context(), contextKeyPathParams, m))
+	}
+
+	rt.handler.ServeHTTP(rw, req)
+}
@@ -0,0 +1,128 @@
+package summer
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRoutePattern(t *testing.T) {
+	method, path, segs, err := parseRoutePattern("GET /users/{id}/files/{path...}")
+	require.NoError(t, err)
+	require.Equal(t, "GET", method)
+	require.Equal(t, "/users/{id}/files/{path...}", path)
+	require.Equal(t, []routeSegment{
+		{kind: routeSegmentStatic, value: "users"},
+		{kind: routeSegmentParam, value: "id"},
+		{kind: routeSegmentStatic, value: "files"},
+		{kind: routeSegmentWildcard, value: "path"},
+	}, segs)
+
+	_, _, segs, err = parseRoutePattern("/static/")
+	require.NoError(t, err)
+	require.Equal(t, []routeSegment{
+		{kind: routeSegmentStatic, value: "static"},
+		{kind: routeSegmentWildcard},
+	}, segs)
+
+	for _, pattern := range []string{
+		"users",
+		"get /users",
+		"/users//{id}",
+		"/users/{id",
+		"/users/{}",
+		"/users/{id}/{id}",
+		"/users/{path...}/files",
+		"/{$}/users",
+	} {
+		_, _, _, err = parseRoutePattern(pattern)
+		require.Error(t, err, pattern)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	r := newRouter()
+
+	var hit string
+
+	handle := func(pattern string) {
+		r.Handle(pattern, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			hit = pattern
+			for k, v := range pathParamsFromContext(req.Context()) {
+				hit += " " + k + "=" + v
+			}
+		}))
+	}
+
+	handle("/{$}")
+	handle("GET /users/{id}")
+	handle("DELETE /users/{id}")
+	handle("GET /users/me")
+	handle("/files/{path...}")
+	handle("/static/")
+
+	require.Panics(t, func() { handle("GET /users/{name}") })
+
+	cases := []struct {
+		method string
+		path   string
+		hit    string
+		code   int
+	}{
+		{"GET", "/", "/{$}", http.StatusOK},
+		{"GET", "/users/1", "GET /users/{id} id=1", http.StatusOK},
+		{"HEAD", "/users/1", "GET /users/{id} id=1", http.StatusOK},
+		{"DELETE", "/users/1", "DELETE /users/{id} id=1", http.StatusOK},
+		{"GET", "/users/me", "GET /users/me", http.StatusOK},
+		{"POST", "/users/1", "", http.StatusMethodNotAllowed},
+		{"GET", "/users/", "", http.StatusNotFound},
+		{"GET", "/files/a/b/c", "/files/{path...} path=a/b/c", http.StatusOK},
+		{"PUT", "/static/a/b", "/static/", http.StatusOK},
+		{"GET", "/nothing", "", http.StatusNotFound},
+	}
+
+	for _, item := range cases {
+		hit = ""
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, httptest.NewRequest(item.method, "https://example.com"+item.path, nil))
+		require.Equal(t, item.code, rw.Code, item.method+" "+item.path)
+		require.Equal(t, item.hit, hit, item.method+" "+item.path)
+	}
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, httptest.NewRequest("POST", "https://example.com/users/1", nil))
+	require.Equal(t, "DELETE, GET, HEAD", rw.Header().Get("Allow"))
+}
+
+func TestRouterCleanPath(t *testing.T) {
+	require.Equal(t, "/", cleanPath(""))
+	require.Equal(t, "/a", cleanPath("a"))
+	require.Equal(t, "/admin/x", cleanPath("/public/../admin/x"))
+	require.Equal(t, "/a/b/", cleanPath("//a/./b/"))
+
+	r := newRouter()
+	r.notFound = http.NotFoundHandler()
+	r.Handle("GET /public/{p...}", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("public"))
+	}))
+
+	for _, item := range []struct {
+		target   string
+		location string
+	}{
+		{"/public/../admin/x", "/admin/x"},
+		{"/public/%2e%2e/admin/x?a=b", "/admin/x?a=b"},
+		{"/public//a", "/public/a"},
+	} {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", item.target, nil)
+		r.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusMovedPermanently, rw.Code, item.target)
+		require.Equal(t, item.location, rw.Header().Get("Location"), item.target)
+	}
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/public/a/", nil)
+	r.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "public", rw.Body.String())
+}
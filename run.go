@@ -0,0 +1,284 @@
+package summer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type runOptions struct {
+	addr         string
+	listener     net.Listener
+	server       func(s *http.Server)
+	signals      []os.Signal
+	preStopDelay time.Duration
+	drainTimeout time.Duration
+}
+
+// RunOption configuration function for [App.Run]
+type RunOption func(o *runOptions)
+
+// RunWithAddr a [RunOption] setting address to listen, default to ":8080"
+func RunWithAddr(addr string) RunOption {
+	return func(o *runOptions) {
+		o.addr = addr
+	}
+}
+
+// RunWithListener a [RunOption] serving on an existing listener instead of listening on address,
+// the listener is closed by [App.Run]
+func RunWithListener(l net.Listener) RunOption {
+	return func(o *runOptions) {
+		o.listener = l
+	}
+}
+
+// RunWithServer a [RunOption] customizing the [http.Server] before serving, like setting timeouts,
+// Handler is always the [App]
+func RunWithServer(fn func(s *http.Server)) RunOption {
+	return func(o *runOptions) {
+		o.server = fn
+	}
+}
+
+// RunWithSignals a [RunOption] setting signals triggering graceful shutdown, default to SIGINT and SIGTERM
+//
+// A second signal, or ctx done, during pre-stop delay skips the rest of it, a signal after that terminates
+// the process as usual
+func RunWithSignals(signals ...os.Signal) RunOption {
+	return func(o *runOptions) {
+		o.signals = signals
+	}
+}
+
+// RunWithPreStopDelay a [RunOption] setting duration between readiness check starting to fail and server
+// stopping accepting requests, allowing load balancers and service meshes to notice, default to 0
+func RunWithPreStopDelay(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.preStopDelay = d
+	}
+}
+
+// RunWithDrainTimeout a [RunOption] setting maximum duration waiting for in-flight requests to complete,
+// remaining connections are closed forcibly after it, the same timeout is applied to [Registry.Shutdown],
+// default to 30 seconds
+func RunWithDrainTimeout(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.drainTimeout = d
+	}
+}
+
+func (a *app[T]) Run(ctx context.Context, opts ...RunOption) (err error) {
+	o := runOptions{
+		addr:         ":8080",
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+		drainTimeout: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l := o.listener
+	if l == nil {
+		if l, err = net.Listen("tcp", o.addr); err != nil {
+			return
+		}
+	}
+
+	if err = a.Startup(ctx); err != nil {
+		_ = l.Close()
+		return
+	}
+
+	s := &http.Server{}
+	if o.server != nil {
+		o.server(s)
+	}
+	s.Handler = a
+	// long-lived handlers are not drained by [http.Server.Shutdown], signal them to stop
+	s.RegisterOnShutdown(a.drain.stop)
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- s.Serve(l)
+	}()
+
+	chSig := make(chan os.Signal, 1)
+	if len(o.signals) > 0 {
+		signal.Notify(chSig, o.signals...)
+	}
+
+	done := ctx.Done()
+	select {
+	case err = <-chErr:
+		// serving failed, nothing to drain
+		signal.Stop(chSig)
+		_ = s.Close()
+		return combineErrors(err, a.shutdownComponents(o.drainTimeout))
+	case <-chSig:
+	case <-done:
+		// stays done, only a signal cuts pre-stop delay short
+		done = nil
+	}
+
+	// fail readiness checks, then wait for load balancers to notice
+	atomic.StoreInt32(&a.stopping, 1)
+	if o.preStopDelay > 0 {
+		t := time.NewTimer(o.preStopDelay)
+		select {
+		case <-t.C:
+		case <-chSig:
+		case <-done:
+		}
+		t.Stop()
+	}
+	signal.Stop(chSig)
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), o.drainTimeout)
+	defer drainCancel()
+
+	if err = s.Shutdown(drainCtx); err != nil {
+		_ = s.Close()
+	}
+
+	// hijacked connections must be closed before their dependencies, stop is a no-op if already done by hook
+	a.drain.stop()
+	if errWait := a.drain.wait(drainCtx); err == nil {
+		err = errWait
+	}
+
+	return combineErrors(err, a.shutdownComponents(o.drainTimeout))
+}
+
+// shutdownComponents run [Registry.Shutdown] with timeout
+func (a *app[T]) shutdownComponents(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return a.Shutdown(ctx)
+}
+
+// drainer signals long-lived handlers to stop when [App.Run] shuts down, since [http.Server.Shutdown] neither
+// interrupts streaming responses nor tracks hijacked connections
+type drainer struct {
+	done chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+	conns   map[*WebSocketConn]struct{}
+	wg      sync.WaitGroup
+}
+
+func newDrainer() *drainer {
+	return &drainer{
+		done:  make(chan struct{}),
+		conns: map[*WebSocketConn]struct{}{},
+	}
+}
+
+// drainerFromContext returns [drainer] of the outermost [App] this request went through
+func drainerFromContext(ctx context.Context) *drainer {
+	d, _ := ctx.Value(contextKeyDrainer).(*drainer)
+	return d
+}
+
+// watchDrain cancel context of a long-lived handler once [App.Run] starts shutting down, see [Context.Done]
+func (c *basicContext) watchDrain() {
+	d := drainerFromContext(c.req.Context())
+	if d == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.req.Context())
+	c.req = c.req.WithContext(ctx)
+	c.cancel = cancel
+	go func() {
+		select {
+		case <-d.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// stopping check if long-lived handlers are signaled to stop, new upgrades should be rejected
+func (d *drainer) stopping() bool {
+	select {
+	case <-d.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// track add an upgraded connection, returns false without tracking it if already stopped
+func (d *drainer) track(ws *WebSocketConn) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return false
+	}
+	d.conns[ws] = struct{}{}
+	d.wg.Add(1)
+	return true
+}
+
+// untrack remove an upgraded connection closed by [Context.Perform]
+func (d *drainer) untrack(ws *WebSocketConn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.conns[ws]; ok {
+		delete(d.conns, ws)
+		d.wg.Done()
+	}
+}
+
+// stop cancel context of long-lived handlers, and send close frame with [WebSocketCloseGoingAway]
+// to upgraded connections, handlers are expected to return after peer replies
+func (d *drainer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.stopped = true
+	close(d.done)
+	for ws := range d.conns {
+		_ = ws.sendClose(WebSocketCloseGoingAway, "server shutting down")
+	}
+}
+
+// wait wait for upgraded connections to be closed, remaining ones are closed forcibly once ctx is done
+func (d *drainer) wait(ctx context.Context) error {
+	ch := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	if len(d.conns) == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	conns := make([]*WebSocketConn, 0, len(d.conns))
+	for ws := range d.conns {
+		conns = append(conns, ws)
+	}
+	d.mu.Unlock()
+
+	for _, ws := range conns {
+		ws.shutdown()
+	}
+	return ctx.Err()
+}
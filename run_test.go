@@ -0,0 +1,280 @@
+package summer
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppRun(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, s)
+	}
+
+	a := Basic()
+	for _, name := range []string{"db", "cache"} {
+		name := name
+		a.Component(name).Startup(func(ctx context.Context) error {
+			record("startup " + name)
+			return nil
+		}).Shutdown(func(ctx context.Context) error {
+			record("shutdown " + name)
+			return nil
+		})
+	}
+	a.Component("noop")
+
+	started, release := make(chan struct{}), make(chan struct{})
+	a.HandleFunc("/slow", func(c Context) {
+		close(started)
+		<-release
+		record("served")
+		c.Text("done")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	base := "http://" + l.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chRun := make(chan error, 1)
+	go func() {
+		chRun <- a.Run(ctx, RunWithListener(l), RunWithPreStopDelay(10*time.Millisecond), RunWithSignals())
+	}()
+
+	res, err := http.Get(base + DefaultReadinessPath)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	chSlow := make(chan string, 1)
+	go func() {
+		res, err := http.Get(base + "/slow")
+		if err != nil {
+			chSlow <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		buf, _ := io.ReadAll(res.Body)
+		chSlow <- string(buf)
+	}()
+	<-started
+
+	cancel()
+
+	// readiness fails while draining, liveness keeps OK, checked on the app directly since listener may be closed
+	check := func(path string) int {
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, httptest.NewRequest("GET", path, nil))
+		return rw.Code
+	}
+	require.Eventually(t, func() bool {
+		return check(DefaultReadinessPath) == http.StatusServiceUnavailable
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, http.StatusOK, check(DefaultLivenessPath))
+
+	// in-flight request is drained before components shut down
+	close(release)
+	require.Equal(t, "done", <-chSlow)
+	require.NoError(t, <-chRun)
+	require.Equal(t, []string{"startup db", "startup cache", "served", "shutdown cache", "shutdown db"}, events)
+
+	_, err = http.Get(base + DefaultReadinessPath)
+	require.Error(t, err)
+}
+
+func TestAppRunStartupFailed(t *testing.T) {
+	a := Basic()
+	a.Component("db").Startup(func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	err = a.Run(context.Background(), RunWithListener(l))
+	require.EqualError(t, err, "connection refused")
+
+	_, err = l.Accept()
+	require.Error(t, err)
+}
+
+func TestAppRunDrainTimeout(t *testing.T) {
+	a := Basic()
+	a.Component("db").Shutdown(func(ctx context.Context) error {
+		return errors.New("db shutdown failed")
+	})
+	started := make(chan struct{})
+	a.HandleFunc("/hang", func(c Context) {
+		close(started)
+		<-c.Done()
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chRun := make(chan error, 1)
+	go func() {
+		chRun <- a.Run(ctx, RunWithListener(l), RunWithDrainTimeout(100*time.Millisecond), RunWithSignals())
+	}()
+
+	go func() {
+		res, err := http.Get("http://" + l.Addr().String() + "/hang")
+		if err == nil {
+			_ = res.Body.Close()
+		}
+	}()
+	<-started
+
+	cancel()
+	require.EqualError(t, <-chRun, context.DeadlineExceeded.Error()+"; db shutdown failed")
+}
+
+func TestAppRunLongLived(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, s)
+	}
+
+	a := Basic()
+	a.Component("db").Shutdown(func(ctx context.Context) error {
+		record("shutdown db")
+		return nil
+	})
+	a.HandleFunc("/sse", func(c Context) {
+		_ = c.SSE().Send(SSEEvent{Data: "hello"})
+		<-c.Done()
+		record("sse done")
+	})
+	a.HandleFunc("/ws", func(c Context) {
+		ws := c.Upgrade(WebSocketWithPingInterval(0))
+		_, _, err := ws.ReadMessage()
+		record("ws done: " + err.Error())
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	base := "http://" + l.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chRun := make(chan error, 1)
+	go func() {
+		chRun <- a.Run(ctx, RunWithListener(l), RunWithDrainTimeout(10*time.Second), RunWithSignals())
+	}()
+
+	res, err := http.Get(base + "/sse")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	br := bufio.NewReader(res.Body)
+	line, err := br.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "data: hello\n", line)
+
+	c, wres := dialTestWebSocket(t, base, "/ws")
+	defer c.conn.Close()
+	require.Equal(t, http.StatusSwitchingProtocols, wres.StatusCode)
+
+	begin := time.Now()
+	cancel()
+
+	// going away, then reply the close handshake
+	op, payload := c.read(t)
+	require.Equal(t, byte(wsOpClose), op)
+	require.Equal(t, uint16(WebSocketCloseGoingAway), binary.BigEndian.Uint16(payload))
+	c.write(t, true, wsOpClose, payload[:2])
+
+	_, err = io.ReadAll(br)
+	require.NoError(t, err)
+
+	require.NoError(t, <-chRun)
+	require.Less(t, time.Since(begin), 5*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.ElementsMatch(t, []string{"sse done", "ws done: websocket: close 1001"}, events[:2])
+	require.Equal(t, []string{"shutdown db"}, events[2:])
+}
+
+func TestAppRunPreStopDelaySignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending signals to self is not supported on windows")
+	}
+
+	a := Basic()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chRun := make(chan error, 1)
+	go func() {
+		chRun <- a.Run(ctx, RunWithListener(l), RunWithPreStopDelay(time.Minute), RunWithSignals(os.Interrupt))
+	}()
+
+	res, err := http.Get("http://" + l.Addr().String() + DefaultReadinessPath)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	cancel()
+	require.Eventually(t, func() bool {
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, httptest.NewRequest("GET", DefaultReadinessPath, nil))
+		return rw.Code == http.StatusServiceUnavailable
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// a signal skips the rest of pre-stop delay
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, p.Signal(os.Interrupt))
+	select {
+	case err = <-chRun:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pre-stop delay not interrupted by signal")
+	}
+}
+
+func TestDrainerStopped(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/ws", func(c Context) {
+		c.Upgrade()
+	})
+	a.(*app[Context]).drain.stop()
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	c, res := dialTestWebSocket(t, s.URL, "/ws")
+	defer c.conn.Close()
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	// not tracked after stopped
+	d := newDrainer()
+	d.stop()
+	require.False(t, d.track(&WebSocketConn{}))
+	require.Empty(t, d.conns)
+	require.NoError(t, d.wait(context.Background()))
+}
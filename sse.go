@@ -0,0 +1,158 @@
+package summer
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSEEvent a single server-sent event, empty fields are omitted
+type SSEEvent struct {
+	// ID sets the last event id of client
+	ID string
+	// Event event type, client defaults to "message"
+	Event string
+	// Retry reconnection time of client
+	Retry time.Duration
+	// Data event data, multiple lines are sent as multiple "data" fields
+	Data string
+}
+
+// SSEWriter writes server-sent events, see [Context.SSE]
+type SSEWriter interface {
+	// Send write an event and flush it, returns an error if client is gone
+	Send(evt SSEEvent) error
+
+	// Comment write a comment line and flush it, comments are ignored by clients
+	Comment(s string) error
+}
+
+var errSSEClosed = errors.New("sse stream closed")
+
+type sseWriter struct {
+	c *basicContext
+
+	mu     sync.Mutex
+	closed bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// sseEscape remove line breaks from a single line field
+func sseEscape(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func (w *sseWriter) write(s string) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errSSEClosed
+	}
+	if err = w.c.Err(); err != nil {
+		return
+	}
+	if _, err = w.c.rw.Write([]byte(s)); err != nil {
+		return
+	}
+	if f, ok := w.c.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+	return
+}
+
+func (w *sseWriter) Send(evt SSEEvent) error {
+	sb := &strings.Builder{}
+	if evt.ID != "" {
+		sb.WriteString("id: " + sseEscape(evt.ID) + "\n")
+	}
+	if evt.Event != "" {
+		sb.WriteString("event: " + sseEscape(evt.Event) + "\n")
+	}
+	if evt.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(evt.Retry.Milliseconds(), 10) + "\n")
+	}
+	lines := strings.Split(strings.ReplaceAll(evt.Data, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		sb.WriteString("data: " + strings.ReplaceAll(line, "\r", "") + "\n")
+	}
+	sb.WriteString("\n")
+	return w.write(sb.String())
+}
+
+func (w *sseWriter) Comment(s string) error {
+	sb := &strings.Builder{}
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		sb.WriteString(": " + strings.ReplaceAll(line, "\r", "") + "\n")
+	}
+	sb.WriteString("\n")
+	return w.write(sb.String())
+}
+
+// heartbeat send comments periodically, keeping idle connections alive through proxies
+func (w *sseWriter) heartbeat(d time.Duration) {
+	defer w.wg.Done()
+
+	t := time.NewTicker(d)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.c.Done():
+			return
+		case <-t.C:
+			if w.write(":\n\n") != nil {
+				return
+			}
+		}
+	}
+}
+
+// close stop heartbeat and reject further writes, response must not be touched after [Context.Perform] returns
+func (w *sseWriter) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+}
+
+func (c *basicContext) SSE() SSEWriter {
+	if c.sse != nil {
+		return c.sse
+	}
+
+	// headers are sent immediately, the buffered response is discarded
+	c.sendOnce.Do(func() {})
+
+	h := c.rw.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentTypeTextEventStream)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	h.Set("X-Content-Type-Options", "nosniff")
+	c.rw.WriteHeader(c.code)
+	if f, ok := c.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	c.watchDrain()
+
+	c.sse = &sseWriter{c: c, stop: make(chan struct{})}
+
+	if c.opts.sseHeartbeat > 0 {
+		c.sse.wg.Add(1)
+		go c.sse.heartbeat(c.opts.sseHeartbeat)
+	}
+
+	return c.sse
+}
@@ -0,0 +1,70 @@
+package summer
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextSSE(t *testing.T) {
+	a := Basic(WithSSEHeartbeat(0))
+	a.HandleFunc("/events", func(c Context) {
+		c.Text("discarded")
+		w := c.SSE()
+		require.NoError(t, w.Send(SSEEvent{ID: "1\n", Event: "greet", Retry: time.Second, Data: "hello\nworld"}))
+		require.NoError(t, w.Comment("ping"))
+		require.NoError(t, w.Send(SSEEvent{}))
+		panic("boom")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil)
+	a.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, ContentTypeTextEventStream, rw.Header().Get("Content-Type"))
+	require.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+	require.Empty(t, rw.Header().Get("Content-Length"))
+	require.True(t, rw.Flushed)
+	require.Equal(t, "id: 1\nevent: greet\nretry: 1000\ndata: hello\ndata: world\n\n: ping\n\ndata: \n\n", rw.Body.String())
+}
+
+func TestContextSSEDisconnect(t *testing.T) {
+	done := make(chan error, 1)
+
+	a := Basic(WithSSEHeartbeat(time.Millisecond * 10))
+	a.HandleFunc("/events", func(c Context) {
+		w := c.SSE()
+		require.NoError(t, w.Send(SSEEvent{Data: "first"}))
+		<-c.Done()
+		done <- w.Send(SSEEvent{Data: "second"})
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/events")
+	require.NoError(t, err)
+
+	r := bufio.NewReader(res.Body)
+	var lines []string
+	for len(lines) < 4 {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	// first event, then a heartbeat
+	require.Equal(t, []string{"data: first", "", ":", ""}, lines)
+
+	_ = res.Body.Close()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("handler not notified of disconnect")
+	}
+}
@@ -0,0 +1,356 @@
+package summer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type staticOptions struct {
+	index        string
+	spa          bool
+	listing      bool
+	cacheControl string
+}
+
+// StaticOption configuration function for [Group.Static]
+type StaticOption func(o *staticOptions)
+
+// StaticWithIndex a [StaticOption] setting index file of directories, default to "index.html"
+func StaticWithIndex(name string) StaticOption {
+	return func(o *staticOptions) {
+		o.index = name
+	}
+}
+
+// StaticWithSPA a [StaticOption] serving the root index file for missing paths without extension,
+// for single page applications with client side routing
+func StaticWithSPA() StaticOption {
+	return func(o *staticOptions) {
+		o.spa = true
+	}
+}
+
+// StaticWithListing a [StaticOption] enabling listing of directories without index file
+func StaticWithListing() StaticOption {
+	return func(o *staticOptions) {
+		o.listing = true
+	}
+}
+
+// StaticWithCacheControl a [StaticOption] setting Cache-Control header of files
+func StaticWithCacheControl(s string) StaticOption {
+	return func(o *staticOptions) {
+		o.cacheControl = s
+	}
+}
+
+// precompressedEncodings content codings of precompressed siblings, in order of preference
+var precompressedEncodings = []struct {
+	coding string
+	ext    string
+}{
+	{coding: "br", ext: ".br"},
+	{coding: "gzip", ext: ".gz"},
+}
+
+// haltFSError halt with status code from a [fs.FS] error
+func haltFSError(err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		HaltString("file not found", HaltWithStatusCode(http.StatusNotFound))
+	case errors.Is(err, fs.ErrPermission):
+		HaltString("permission denied", HaltWithStatusCode(http.StatusForbidden))
+	default:
+		raise(err)
+	}
+}
+
+type contentETagKey struct {
+	fsys fs.FS
+	name string
+	size int64
+}
+
+// contentETagsMax maximum number of entries in contentETags, files are hashed every time once reached
+const contentETagsMax = 4096
+
+var (
+	// contentETags caches content based ETags of files from [embed.FS], which never change
+	contentETags sync.Map
+	// contentETagsCount number of entries in contentETags
+	contentETagsCount int64
+)
+
+// isEmbedFile check if f is opened from an [embed.FS], directly or through [fs.Sub]
+func isEmbedFile(f fs.File) bool {
+	t := reflect.TypeOf(f)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath() == "embed"
+}
+
+// fileETag create an ETag from modification time and size, or from content if modification time is missing,
+// content based ETags are only cached for files from [embed.FS]
+func fileETag(fsys fs.FS, name string, f fs.File, fi fs.FileInfo, rs io.ReadSeeker) (string, error) {
+	if !fi.ModTime().IsZero() {
+		return `"` + strconv.FormatInt(fi.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(fi.Size(), 16) + `"`, nil
+	}
+
+	cacheable := isEmbedFile(f) && reflect.TypeOf(fsys).Comparable()
+	key := contentETagKey{fsys: fsys, name: name, size: fi.Size()}
+	if cacheable {
+		if v, ok := contentETags.Load(key); ok {
+			return v.(string), nil
+		}
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rs); err != nil {
+		return "", err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+
+	if cacheable && atomic.LoadInt64(&contentETagsCount) < contentETagsMax {
+		if _, loaded := contentETags.LoadOrStore(key, etag); !loaded {
+			atomic.AddInt64(&contentETagsCount, 1)
+		}
+	}
+	return etag, nil
+}
+
+// contentTypeByName returns content type by extension of name, or empty
+func contentTypeByName(name string) string {
+	return mime.TypeByExtension(path.Ext(name))
+}
+
+// serveContent send content with [http.ServeContent], supporting byte ranges and conditional requests
+func (c *basicContext) serveContent(name string, modTime time.Time, rs io.ReadSeeker) {
+	c.rw.Header().Del("Content-Length")
+	c.sendOnce.Do(func() {
+		http.ServeContent(c.rw, c.req, name, modTime, rs)
+	})
+}
+
+func (c *basicContext) SendFileFS(fsys fs.FS, name string) {
+	var (
+		f      fs.File
+		fi     fs.FileInfo
+		err    error
+		coding string
+		opened = name
+		vary   bool
+		accept = c.req.Header.Get("Accept-Encoding")
+	)
+
+	h := c.rw.Header()
+
+	for _, pc := range precompressedEncodings {
+		if _, err = fs.Stat(fsys, name+pc.ext); err != nil {
+			continue
+		}
+		vary = true
+		if coding != "" || !acceptsEncoding(accept, pc.coding) {
+			continue
+		}
+		if f, err = fsys.Open(name + pc.ext); err == nil {
+			coding, opened = pc.coding, name+pc.ext
+		}
+	}
+	if vary {
+		addVary(h, "Accept-Encoding")
+	}
+
+	if f == nil {
+		if f, err = fsys.Open(name); err != nil {
+			haltFSError(err)
+		}
+	}
+	defer f.Close()
+
+	if fi, err = f.Stat(); err != nil {
+		haltFSError(err)
+	}
+	if fi.IsDir() {
+		HaltString("file not found", HaltWithStatusCode(http.StatusNotFound))
+	}
+
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		var buf []byte
+		if buf, err = io.ReadAll(f); err != nil {
+			raise(err)
+		}
+		rs = bytes.NewReader(buf)
+	}
+
+	if ct := contentTypeByName(name); ct != "" {
+		h.Set("Content-Type", ct)
+	} else if coding != "" {
+		// sniffing compressed content makes no sense
+		h.Set("Content-Type", "application/octet-stream")
+	}
+	if coding != "" {
+		h.Set("Content-Encoding", coding)
+	}
+	if h.Get("ETag") == "" {
+		var etag string
+		if etag, err = fileETag(fsys, opened, f, fi, rs); err != nil {
+			raise(err)
+		}
+		h.Set("ETag", etag)
+	}
+
+	c.serveContent(name, fi.ModTime(), rs)
+}
+
+func (c *basicContext) SendFile(file string) {
+	c.SendFileFS(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+}
+
+func (c *basicContext) Attachment(name string, r io.Reader) {
+	h := c.rw.Header()
+
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": name}); v != "" {
+		h.Set("Content-Disposition", v)
+	} else {
+		h.Set("Content-Disposition", "attachment")
+	}
+
+	ct := contentTypeByName(name)
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+
+	if rs, ok := r.(io.ReadSeeker); ok {
+		h.Set("Content-Type", ct)
+		c.serveContent(name, time.Time{}, rs)
+		return
+	}
+
+	c.Stream(ct, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// staticRedirect redirect relatively, works under any mount prefix
+func staticRedirect(c Context, target string) {
+	if q := c.Req().URL.RawQuery; q != "" {
+		target += "?" + q
+	}
+	c.Res().Header().Set("Location", target)
+	c.Code(http.StatusMovedPermanently)
+}
+
+// serveStatic serve a file or directory from fsys for [Group.Static]
+func serveStatic(c Context, fsys fs.FS, o *staticOptions, name string) {
+	req := c.Req()
+
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+
+	fi, err := fs.Stat(fsys, name)
+	if err != nil {
+		if o.spa && errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+			name = o.index
+			if fi, err = fs.Stat(fsys, name); err != nil {
+				haltFSError(err)
+			}
+		} else {
+			haltFSError(err)
+		}
+	}
+
+	trailingSlash := strings.HasSuffix(req.URL.Path, "/")
+
+	if !fi.IsDir() {
+		if trailingSlash && name != o.index {
+			staticRedirect(c, "../"+path.Base(name))
+			return
+		}
+		if o.cacheControl != "" {
+			c.Res().Header().Set("Cache-Control", o.cacheControl)
+		}
+		c.SendFileFS(fsys, name)
+		return
+	}
+
+	if !trailingSlash {
+		staticRedirect(c, path.Base(req.URL.Path)+"/")
+		return
+	}
+
+	if _, err = fs.Stat(fsys, path.Join(name, o.index)); err == nil {
+		if o.cacheControl != "" {
+			c.Res().Header().Set("Cache-Control", o.cacheControl)
+		}
+		c.SendFileFS(fsys, path.Join(name, o.index))
+		return
+	}
+
+	if !o.listing {
+		HaltString("file not found", HaltWithStatusCode(http.StatusNotFound))
+	}
+
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		haltFSError(err)
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() {
+			n += "/"
+		}
+		u := url.URL{Path: n}
+		sb.WriteString("<a href=\"" + html.EscapeString(u.String()) + "\">" + html.EscapeString(n) + "</a>\n")
+	}
+	sb.WriteString("</pre>\n")
+
+	c.Body("text/html; charset=utf-8", []byte(sb.String()))
+}
+
+func (g *group[T]) Static(prefix string, fsys fs.FS, opts ...StaticOption) {
+	prefix = cleanGroupPrefix(prefix)
+
+	o := &staticOptions{index: "index.html"}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	g.register("GET "+prefix+"/{path...}", func(c T) {
+		serveStatic(c, fsys, o, pathParamsFromContext(c.Req().Context())["path"])
+	}, nil)
+
+	if g.prefix+prefix != "" {
+		// prefix may be empty in a group, the group prefix itself is redirected then
+		g.registerComposed("GET "+g.prefix+prefix, func(c T) {
+			staticRedirect(c, path.Base(c.Req().URL.Path)+"/")
+		}, nil)
+	}
+}
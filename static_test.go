@@ -0,0 +1,209 @@
+package summer
+
+import (
+	"embed"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestAcceptsEncoding(t *testing.T) {
+	require.True(t, acceptsEncoding("gzip, br", "br"))
+	require.True(t, acceptsEncoding("GZIP;q=0.5", "gzip"))
+	require.False(t, acceptsEncoding("gzip;q=0, *", "gzip"))
+	require.True(t, acceptsEncoding("deflate, *;q=0.1", "br"))
+	require.False(t, acceptsEncoding("", "gzip"))
+}
+
+//go:embed static_test.go
+var staticTestFS embed.FS
+
+func TestFileETagCache(t *testing.T) {
+	etag := func(fsys fs.FS, name string) string {
+		f, err := fsys.Open(name)
+		require.NoError(t, err)
+		defer f.Close()
+		fi, err := f.Stat()
+		require.NoError(t, err)
+		s, err := fileETag(fsys, name, f, fi, f.(io.ReadSeeker))
+		require.NoError(t, err)
+		return s
+	}
+
+	// content of other file systems may change without modification time
+	fsys := &struct{ fstest.MapFS }{fstest.MapFS{"a.txt": {Data: []byte("aaa")}}}
+	e1 := etag(fsys, "a.txt")
+	fsys.MapFS["a.txt"].Data = []byte("bbb")
+	require.NotEqual(t, e1, etag(fsys, "a.txt"))
+
+	e1 = etag(staticTestFS, "static_test.go")
+	fi, err := fs.Stat(staticTestFS, "static_test.go")
+	require.NoError(t, err)
+	_, ok := contentETags.Load(contentETagKey{fsys: staticTestFS, name: "static_test.go", size: fi.Size()})
+	require.True(t, ok)
+
+	// files are still from embed.FS through wrappers like fs.Sub
+	wrapped := struct{ fs.FS }{staticTestFS}
+	require.Equal(t, e1, etag(wrapped, "static_test.go"))
+	_, ok = contentETags.Load(contentETagKey{fsys: wrapped, name: "static_test.go", size: fi.Size()})
+	require.True(t, ok)
+}
+
+func TestGroupStatic(t *testing.T) {
+	modTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte("<h1>index</h1>")},
+		"app.js":         {Data: []byte("console.log(1)"), ModTime: modTime},
+		"app.js.gz":      {Data: []byte("gzipped"), ModTime: modTime},
+		"assets/a.txt":   {Data: []byte("aaa")},
+		"assets/<b>.txt": {Data: []byte("bbb")},
+	}
+
+	a := Basic()
+	a.Use(func(h HandlerFunc[Context]) HandlerFunc[Context] {
+		return func(c Context) {
+			c.Res().Header().Set("X-Middleware", "ok")
+			h(c)
+		}
+	})
+	a.Static("/admin", fsys, StaticWithSPA(), StaticWithCacheControl("no-cache"))
+	a.Group("/files").Static("/", fsys, StaticWithListing())
+
+	do := func(method string, path string, header ...string) *httptest.ResponseRecorder {
+		rw, req := httptest.NewRecorder(), httptest.NewRequest(method, path, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		a.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := do("GET", "/admin?a=b")
+	require.Equal(t, http.StatusMovedPermanently, rw.Code)
+	require.Equal(t, "admin/?a=b", rw.Header().Get("Location"))
+
+	rw = do("GET", "/admin/")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "ok", rw.Header().Get("X-Middleware"))
+	require.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+	require.Contains(t, rw.Header().Get("Content-Type"), "text/html")
+	require.Equal(t, "<h1>index</h1>", rw.Body.String())
+	etag := rw.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rw = do("GET", "/admin/", "If-None-Match", etag)
+	require.Equal(t, http.StatusNotModified, rw.Code)
+
+	// spa fallback
+	rw = do("GET", "/admin/users/1")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "<h1>index</h1>", rw.Body.String())
+
+	rw = do("GET", "/admin/missing.js")
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	// precompressed
+	rw = do("GET", "/admin/app.js", "Accept-Encoding", "gzip")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+	require.Contains(t, rw.Header().Get("Content-Type"), "javascript")
+	require.Equal(t, "gzipped", rw.Body.String())
+
+	rw = do("GET", "/admin/app.js")
+	require.Empty(t, rw.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+	require.Equal(t, modTime.Format(http.TimeFormat), rw.Header().Get("Last-Modified"))
+	require.Equal(t, "console.log(1)", rw.Body.String())
+
+	rw = do("GET", "/admin/app.js", "If-Modified-Since", modTime.Format(http.TimeFormat))
+	require.Equal(t, http.StatusNotModified, rw.Code)
+
+	rw = do("GET", "/admin/app.js", "Range", "bytes=0-6")
+	require.Equal(t, http.StatusPartialContent, rw.Code)
+	require.Equal(t, "console", rw.Body.String())
+
+	rw = do("HEAD", "/admin/app.js")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "14", rw.Header().Get("Content-Length"))
+	require.Empty(t, rw.Body.String())
+
+	rw = do("GET", "/admin/app.js/")
+	require.Equal(t, http.StatusMovedPermanently, rw.Code)
+	require.Equal(t, "../app.js", rw.Header().Get("Location"))
+
+	// directory listing
+	rw = do("GET", "/admin/assets/")
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = do("GET", "/files/assets")
+	require.Equal(t, http.StatusMovedPermanently, rw.Code)
+	require.Equal(t, "assets/", rw.Header().Get("Location"))
+
+	rw = do("GET", "/files/assets/")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `<a href="a.txt">a.txt</a>`)
+	require.Contains(t, rw.Body.String(), `<a href="%3Cb%3E.txt">&lt;b&gt;.txt</a>`)
+
+	// path is cleaned by router before matching
+	rw = do("GET", "/files/../index.html")
+	require.Equal(t, http.StatusMovedPermanently, rw.Code)
+	require.Equal(t, "/index.html", rw.Header().Get("Location"))
+
+	rw = do("POST", "/files/index.html")
+	require.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
+
+func TestContextAttachment(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello world"), 0644))
+
+	a := Basic()
+	a.HandleFunc("/file", func(c Context) {
+		c.SendFile(filepath.Join(dir, "hello.txt"))
+	})
+	a.HandleFunc("/missing", func(c Context) {
+		c.SendFile(filepath.Join(dir, "missing.txt"))
+	})
+	a.HandleFunc("/seeker", func(c Context) {
+		c.Attachment("报告.csv", strings.NewReader("a,b\n1,2\n"))
+	})
+	a.HandleFunc("/reader", func(c Context) {
+		c.Attachment("data", io.LimitReader(strings.NewReader("abcdef"), 3))
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/file", nil)
+	req.Header.Set("Range", "bytes=6-")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusPartialContent, rw.Code)
+	require.Equal(t, "world", rw.Body.String())
+	require.NotEmpty(t, rw.Header().Get("ETag"))
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/seeker", nil)
+	req.Header.Set("Range", "bytes=0-2")
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusPartialContent, rw.Code)
+	require.Equal(t, "a,b", rw.Body.String())
+	require.Equal(t, "attachment; filename*=utf-8''%E6%8A%A5%E5%91%8A.csv", rw.Header().Get("Content-Disposition"))
+	require.Contains(t, rw.Header().Get("Content-Type"), "text/csv")
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/reader", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, "abc", rw.Body.String())
+	require.Equal(t, "application/octet-stream", rw.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename=data`, rw.Header().Get("Content-Disposition"))
+}
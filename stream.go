@@ -0,0 +1,203 @@
+package summer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// StreamErrorTrailer trailer reporting an error occurred after a [Context.Stream] response started
+	StreamErrorTrailer = "X-Stream-Error"
+)
+
+var errResponseSent = errors.New("response already sent")
+
+// streamWriter writes response lazily, headers are sent with the first byte
+//
+// Written data is flushed by a ticker of [WithStreamFlushInterval], a handler stalling after a write
+// is not holding data in buffers
+type streamWriter struct {
+	c           *basicContext
+	contentType string
+
+	started bool
+
+	// mu serializes writing and flushing from ticker
+	mu    sync.Mutex
+	dirty bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+
+	cw CompressWriter
+}
+
+// start send headers, invoked at most once by sendOnce
+func (w *streamWriter) start() {
+	w.started = true
+	w.c.streamed = true
+
+	h := w.c.rw.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", w.contentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	// declared trailer forces chunked encoding in HTTP/1.1
+	h.Set("Trailer", StreamErrorTrailer)
+	w.cw = w.c.compressStream()
+	w.c.rw.WriteHeader(w.c.code)
+
+	if d := w.c.opts.streamFlushInterval; d > 0 {
+		w.stop = make(chan struct{})
+		w.wg.Add(1)
+		go w.flushPeriodically(d)
+	}
+}
+
+// flushPeriodically flush written data on every tick, until [streamWriter.close]
+func (w *streamWriter) flushPeriodically(d time.Duration) {
+	defer w.wg.Done()
+
+	t := time.NewTicker(d)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			if w.dirty {
+				w.flush()
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// close stop flushing from ticker, response must not be touched by ticker after [Context.Stream] returns
+func (w *streamWriter) close() {
+	if w.stop != nil {
+		close(w.stop)
+		w.wg.Wait()
+		w.stop = nil
+	}
+}
+
+func (w *streamWriter) Write(p []byte) (n int, err error) {
+	if !w.started {
+		w.c.sendOnce.Do(w.start)
+		if !w.started {
+			return 0, errResponseSent
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cw != nil {
+		n, err = w.cw.Write(p)
+	} else {
+		n, err = w.c.rw.Write(p)
+	}
+	if err != nil {
+		return
+	}
+	if w.stop == nil {
+		// no ticker, flush on every write
+		w.flush()
+	} else {
+		w.dirty = true
+	}
+	return
+}
+
+// Flush flush written data to client immediately
+func (w *streamWriter) Flush() {
+	if !w.started {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+}
+
+// flush flush written data with mu held
+func (w *streamWriter) flush() {
+	if w.cw != nil {
+		_ = w.cw.Flush()
+	}
+	if f, ok := w.c.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+	w.dirty = false
+}
+
+// NDJSONEncoder writes newline delimited JSON records, see [Context.NDJSON]
+type NDJSONEncoder struct {
+	w   *streamWriter
+	enc *json.Encoder
+}
+
+// Encode write a single record as a line
+func (e *NDJSONEncoder) Encode(v any) error {
+	return e.enc.Encode(v)
+}
+
+// Flush flush written records to client immediately
+func (e *NDJSONEncoder) Flush() {
+	e.w.Flush()
+}
+
+func (c *basicContext) Stream(contentType string, fn func(w io.Writer) error) {
+	w := &streamWriter{c: c, contentType: contentType}
+	defer w.close()
+
+	err := fn(w)
+
+	if !w.started {
+		// nothing written, a normal response is still possible
+		if err != nil {
+			raise(err)
+		}
+		w.c.sendOnce.Do(w.start)
+	}
+
+	w.close()
+
+	if w.cw != nil {
+		if cerr := w.cw.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	if err == nil {
+		return
+	}
+
+	if c.req.ProtoAtLeast(1, 1) {
+		if c.opts.productionMode {
+			c.ensureTraceID()
+		}
+		msg := err.Error()
+		if ce := c.concealError(err); ce != err {
+			msg = ce.Error() + " (" + HaltExtraKeyTraceID + ": " + TraceIDFromContext(c) + ")"
+		}
+		c.rw.Header().Set(StreamErrorTrailer, msg)
+		c.observe(err, false)
+		return
+	}
+
+	// no trailer support, the connection is terminated in [Context.Perform]
+	raise(err)
+}
+
+func (c *basicContext) NDJSON(fn func(enc *NDJSONEncoder) error) {
+	c.Stream(ContentTypeApplicationNDJSON, func(w io.Writer) error {
+		sw := w.(*streamWriter)
+		return fn(&NDJSONEncoder{w: sw, enc: json.NewEncoder(sw)})
+	})
+}
@@ -0,0 +1,144 @@
+package summer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextStream(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/stream", func(c Context) {
+		c.Code(http.StatusAccepted)
+		c.Stream("text/csv", func(w io.Writer) error {
+			_, _ = io.WriteString(w, "a,b\n")
+			_, _ = io.WriteString(w, "1,2\n")
+			return nil
+		})
+	})
+	a.HandleFunc("/early", func(c Context) {
+		c.Stream("text/csv", func(w io.Writer) error {
+			return NewHaltError(errors.New("not ready"), HaltWithStatusCode(http.StatusConflict))
+		})
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "/stream", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusAccepted, rw.Code)
+	require.Equal(t, "text/csv", rw.Header().Get("Content-Type"))
+	require.Empty(t, rw.Header().Get("Content-Length"))
+	require.Equal(t, "a,b\n1,2\n", rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "/early", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusConflict, rw.Code)
+	require.Equal(t, ContentTypeApplicationJSONUTF8, rw.Header().Get("Content-Type"))
+	require.Equal(t, `{"message":"not ready"}`, rw.Body.String())
+}
+
+func TestContextNDJSON(t *testing.T) {
+	a := Basic(WithStreamFlushInterval(0))
+	a.HandleFunc("/records", func(c Context) {
+		c.NDJSON(func(enc *NDJSONEncoder) error {
+			for i := 0; i < 3; i++ {
+				if err := enc.Encode(map[string]int{"id": i}); err != nil {
+					return err
+				}
+			}
+			if c.Req().URL.Query().Get("fail") != "" {
+				return errors.New("database gone")
+			}
+			return nil
+		})
+	})
+	a.HandleFunc("/panic", func(c Context) {
+		c.NDJSON(func(enc *NDJSONEncoder) error {
+			_ = enc.Encode(1)
+			panic("boom")
+		})
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/records")
+	require.NoError(t, err)
+	require.Equal(t, ContentTypeApplicationNDJSON, res.Header.Get("Content-Type"))
+	buf, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, "{\"id\":0}\n{\"id\":1}\n{\"id\":2}\n", string(buf))
+	require.Empty(t, res.Trailer.Get(StreamErrorTrailer))
+
+	res, err = http.Get(s.URL + "/records?fail=1")
+	require.NoError(t, err)
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":0}\n", line)
+	_, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, "database gone", res.Trailer.Get(StreamErrorTrailer))
+
+	res, err = http.Get(s.URL + "/panic")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	_, err = io.ReadAll(res.Body)
+	require.Error(t, err)
+	_ = res.Body.Close()
+}
+
+func TestContextStreamFlushDuringStall(t *testing.T) {
+	stalled := make(chan struct{})
+	a := Basic(WithStreamFlushInterval(10 * time.Millisecond))
+	a.HandleFunc("/stream", func(c Context) {
+		c.Stream("text/plain", func(w io.Writer) error {
+			_, _ = io.WriteString(w, "first\n")
+			<-stalled
+			_, _ = io.WriteString(w, "second\n")
+			return nil
+		})
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+	defer close(stalled)
+
+	for _, encoding := range []string{"", "gzip"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		req, err := http.NewRequestWithContext(ctx, "GET", s.URL+"/stream", nil)
+		require.NoError(t, err)
+		if encoding != "" {
+			req.Header.Set("Accept-Encoding", encoding)
+		}
+		res, err := http.DefaultTransport.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, encoding, res.Header.Get("Content-Encoding"))
+
+		var r io.Reader = res.Body
+		if encoding != "" {
+			r, err = gzip.NewReader(res.Body)
+			require.NoError(t, err)
+		}
+		br := bufio.NewReader(r)
+
+		// received while handler is still stalled
+		line, err := br.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "first\n", line)
+
+		stalled <- struct{}{}
+		line, err = br.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "second\n", line)
+		_ = res.Body.Close()
+		cancel()
+	}
+}
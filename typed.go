@@ -0,0 +1,38 @@
+package summer
+
+import "reflect"
+
+// TypedHandlerFunc handler func with typed request and response, easy to test as a plain function
+type TypedHandlerFunc[T Context, Req, Resp any] func(c T, req Req) (Resp, error)
+
+// Typed convert a [TypedHandlerFunc] to [HandlerFunc]
+//
+// Request is bound with [Bind], response is encoded with [Context.Render], and a returned error is handled by [Context.Perform],
+// producing the same status code and body as [StatusCodeFromError] and [BodyFromError]
+func Typed[T Context, Req, Resp any](fn TypedHandlerFunc[T, Req, Resp]) HandlerFunc[T] {
+	return func(c T) {
+		resp, err := fn(c, Bind[Req](c))
+		if err != nil {
+			raise(err)
+		}
+		c.Render(resp)
+	}
+}
+
+// HandleTyped register a [TypedHandlerFunc] to a [Group] or [App], see [Group.HandleFunc] and [Typed]
+//
+// Tags of request type are checked by [MustCheckTags] on registration
+//
+// example:
+//
+//	summer.HandleTyped(a, "POST /users", func(c summer.Context, req CreateUserRequest) (*User, error) {
+//		return createUser(c, req.Name)
+//	})
+func HandleTyped[T Context, Req, Resp any, G Group[T]](g G, pattern string, fn TypedHandlerFunc[T, Req, Resp], mws ...Middleware[T]) {
+	MustCheckTags[Req]()
+	if tg, ok := any(g).(typedGroup[T]); ok {
+		tg.handle(pattern, Typed(fn), mws, reflect.TypeOf((*Req)(nil)).Elem(), reflect.TypeOf((*Resp)(nil)).Elem())
+		return
+	}
+	g.HandleFunc(pattern, Typed(fn), mws...)
+}
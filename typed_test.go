@@ -0,0 +1,82 @@
+package summer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleTyped(t *testing.T) {
+	type Req struct {
+		ID   string `json:"path_id"`
+		Name string `json:"name"`
+	}
+	type Resp struct {
+		Greeting string `json:"greeting"`
+	}
+
+	a := Basic()
+	HandleTyped(a, "GET /users/{id}", func(c Context, req Req) (resp Resp, err error) {
+		if req.Name == "" {
+			err = NewHaltError(errors.New("missing name"), HaltWithBadRequest())
+			return
+		}
+		if req.Name == "bad" {
+			err = errors.New("bad name")
+			return
+		}
+		resp.Greeting = "hello " + req.Name + " " + req.ID
+		return
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/users/1?name=alice", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Equal(t, `{"greeting":"hello alice 1"}`, rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/users/1", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.Equal(t, `{"message":"missing name"}`, rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/users/1?name=bad", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusInternalServerError, rw.Code)
+	require.Equal(t, `{"message":"bad name"}`, rw.Body.String())
+}
+
+func TestHandleTypedInvalidTags(t *testing.T) {
+	require.PanicsWithValue(t, "summer.badBind.name: unknown bind source: body2", func() {
+		HandleTyped(Basic(), "POST /test", func(c Context, req badBind) (any, error) { return nil, nil })
+	})
+	require.PanicsWithValue(t, `summer.badDefault.size: invalid default value: strconv.ParseInt: parsing "big": invalid syntax`, func() {
+		HandleTyped(Basic(), "POST /test", func(c Context, req badDefault) (any, error) { return nil, nil })
+	})
+	require.PanicsWithValue(t, "summer.badValidate.name: unknown validation rule: required2", func() {
+		HandleTyped(Basic(), "POST /test", func(c Context, req *badValidate) (any, error) { return nil, nil })
+	})
+	require.PanicsWithValue(t, "summer.badValidate.name: unknown validation rule: required2", func() {
+		MustCheckTags[struct {
+			Items []badValidate `json:"items"`
+		}]()
+	})
+	require.NotPanics(t, func() {
+		MustCheckTags[struct {
+			Name string `json:"name" validate:"required" default:"x"`
+		}]()
+	})
+}
+
+type badBind struct {
+	Name string `json:"name" bind:"body2"`
+}
+
+type badDefault struct {
+	Size int `json:"size" default:"big"`
+}
+
+type badValidate struct {
+	Name string `json:"name" validate:"required2"`
+}
@@ -1,12 +1,8 @@
 package summer
 
 import (
-	"encoding/json"
-	"errors"
-	"io"
-	"mime"
 	"net/http"
-	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -21,6 +17,18 @@ func respondInternal(rw http.ResponseWriter, s string, code int) {
 	_, _ = rw.Write(buf)
 }
 
+// addVary add a value to Vary header, if not present yet
+func addVary(h http.Header, v string) {
+	for _, item := range h.Values("Vary") {
+		for _, s := range strings.Split(item, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), v) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", v)
+}
+
 func flattenSingleSlice[T any](s []T) any {
 	if len(s) == 1 {
 		return s[0]
@@ -28,58 +36,92 @@ func flattenSingleSlice[T any](s []T) any {
 	return s
 }
 
-func extractRequest(m map[string]any, req *http.Request) (err error) {
-	// header
-	for k, vs := range req.Header {
-		k = "header_" + strings.ToLower(strings.ReplaceAll(k, "-", "_"))
-		m[k] = flattenSingleSlice(vs)
-	}
+type jsonField struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+	asString  bool
+}
 
-	// query
-	for k, vs := range req.URL.Query() {
-		v := flattenSingleSlice(vs)
-		m[k] = v
-		m["query_"+k] = v
-	}
+// jsonFieldsOf collect exported fields of a struct type with names from json tags, embedded structs are flattened
+// and shallower fields shadow deeper ones, the same way [encoding/json] does
+func jsonFieldsOf(t reflect.Type) (fields []jsonField) {
+	seen := map[string]bool{}
 
-	// body
-	var buf []byte
-	if buf, err = io.ReadAll(req.Body); err != nil {
-		return
+	type pending struct {
+		typ   reflect.Type
+		index []int
 	}
 
-	if len(buf) == 0 {
-		return
-	}
+	current := []pending{{typ: t}}
 
-	var contentType string
-	if contentType, _, err = mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil {
-		return
-	}
+	for len(current) > 0 {
+		var (
+			next  []pending
+			found = map[string]bool{}
+		)
 
-	switch contentType {
-	case ContentTypeTextPlain:
-		m["text"] = string(buf)
-	case ContentTypeApplicationJSON:
-		var j map[string]any
-		if err = json.Unmarshal(buf, &j); err != nil {
-			return
-		}
-		for k, v := range j {
-			m[k] = v
-		}
-	case ContentTypeFormURLEncoded:
-		var q url.Values
-		if q, err = url.ParseQuery(string(buf)); err != nil {
-			return
+		for _, p := range current {
+			for i := 0; i < p.typ.NumField(); i++ {
+				sf := p.typ.Field(i)
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+
+				index := append(append([]int{}, p.index...), i)
+
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, pending{typ: ft, index: index})
+					continue
+				}
+
+				if !sf.IsExported() {
+					continue
+				}
+
+				if name == "" {
+					name = sf.Name
+				}
+
+				if seen[name] {
+					continue
+				}
+				found[name] = true
+
+				f := jsonField{
+					name:  name,
+					index: index,
+					typ:   sf.Type,
+				}
+
+				for _, opt := range strings.Split(opts, ",") {
+					switch opt {
+					case "omitempty":
+						f.omitEmpty = true
+					case "string":
+						f.asString = true
+					}
+				}
+
+				fields = append(fields, f)
+			}
 		}
-		for k, vs := range q {
-			m[k] = flattenSingleSlice(vs)
+
+		for name := range found {
+			seen[name] = true
 		}
-	default:
-		err = errors.New("unsupported request body type")
-		return
-	}
 
+		current = next
+	}
 	return
 }
@@ -0,0 +1,345 @@
+package summer
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	HaltExtraKeyErrors = "errors"
+)
+
+// Validator can be implemented by a bound type for cross-field validation, invoked by [Validate] after tag rules
+// passed, a returned error without status code is responded with [http.StatusBadRequest]
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError a single violation reported by [Validate]
+type ValidationError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type validationRule struct {
+	name  string
+	param string
+	num   float64
+	re    *regexp.Regexp
+	oneOf []string
+}
+
+type validationField struct {
+	jsonField
+	omitEmpty bool
+	rules     []validationRule
+	dive      bool
+	elemRules []validationRule
+}
+
+var (
+	structValidationFields sync.Map
+
+	regexpUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// parseValidationRules parse rules like "required,min=1,max=10", "regexp" consumes the rest of tag,
+// and rules after "dive" apply to elements of slice or map
+func parseValidationRules(tag string) (omitEmpty bool, rules []validationRule, dive bool, elemRules []validationRule, err error) {
+	for tag != "" {
+		var item string
+		if strings.HasPrefix(tag, "regexp=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+
+		rule := validationRule{name: name, param: param}
+
+		switch name {
+		case "":
+			continue
+		case "omitempty":
+			if !dive {
+				omitEmpty = true
+			}
+			continue
+		case "dive":
+			dive = true
+			continue
+		case "required", "email", "uuid":
+		case "min", "max", "len":
+			if rule.num, err = strconv.ParseFloat(param, 64); err != nil {
+				err = fmt.Errorf("invalid validation rule %s: %w", item, err)
+				return
+			}
+		case "regexp":
+			if rule.re, err = regexp.Compile(param); err != nil {
+				err = fmt.Errorf("invalid validation rule %s: %w", item, err)
+				return
+			}
+		case "oneof":
+			rule.oneOf = strings.Fields(param)
+		default:
+			err = errors.New("unknown validation rule: " + name)
+			return
+		}
+
+		if dive {
+			elemRules = append(elemRules, rule)
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+	return
+}
+
+// validationFieldsOf returns cached validation fields of a struct type, panics on invalid tags
+func validationFieldsOf(t reflect.Type) []validationField {
+	if v, ok := structValidationFields.Load(t); ok {
+		return v.([]validationField)
+	}
+	var fields []validationField
+	for _, jf := range jsonFieldsOf(t) {
+		f := validationField{jsonField: jf}
+		var err error
+		if f.omitEmpty, f.rules, f.dive, f.elemRules, err = parseValidationRules(t.FieldByIndex(jf.index).Tag.Get("validate")); err != nil {
+			panic(t.String() + "." + jf.name + ": " + err.Error())
+		}
+		fields = append(fields, f)
+	}
+	v, _ := structValidationFields.LoadOrStore(t, fields)
+	return v.([]validationField)
+}
+
+// hasValidationRule check if a struct field has a validation rule
+func hasValidationRule(t reflect.Type, jf jsonField, name string) bool {
+	_, rules, _, _, _ := parseValidationRules(t.FieldByIndex(jf.index).Tag.Get("validate"))
+	for _, rule := range rules {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validationLength(v reflect.Value) (n int, ok bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), true
+	}
+	return
+}
+
+func validationNumber(v reflect.Value) (n float64, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return
+}
+
+// check a single rule, returns a message if violated
+func (rule validationRule) check(v reflect.Value) string {
+	if rule.name == "required" {
+		if !v.IsValid() || v.IsZero() {
+			return "is required"
+		}
+		if n, ok := validationLength(v); ok && n == 0 {
+			return "is required"
+		}
+		return ""
+	}
+
+	// other rules don't apply to nil pointers
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch rule.name {
+	case "min", "max", "len":
+		if n, ok := validationNumber(v); ok {
+			if rule.name == "min" && n < rule.num {
+				return "must be at least " + rule.param
+			}
+			if rule.name == "max" && n > rule.num {
+				return "must be at most " + rule.param
+			}
+			if rule.name == "len" && n != rule.num {
+				return "must be " + rule.param
+			}
+		} else if l, ok := validationLength(v); ok {
+			n := float64(l)
+			if rule.name == "min" && n < rule.num {
+				return "length must be at least " + rule.param
+			}
+			if rule.name == "max" && n > rule.num {
+				return "length must be at most " + rule.param
+			}
+			if rule.name == "len" && n != rule.num {
+				return "length must be " + rule.param
+			}
+		}
+	case "regexp":
+		if v.Kind() == reflect.String && !rule.re.MatchString(v.String()) {
+			return "must match " + rule.param
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, item := range rule.oneOf {
+			if item == s {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(rule.oneOf, ", ")
+	case "email":
+		if v.Kind() == reflect.String {
+			if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+				return "must be a valid email address"
+			}
+		}
+	case "uuid":
+		if v.Kind() == reflect.String && !regexpUUID.MatchString(v.String()) {
+			return "must be a valid uuid"
+		}
+	}
+	return ""
+}
+
+type validation struct {
+	errs []ValidationError
+}
+
+func (vd *validation) rules(path string, v reflect.Value, omitEmpty bool, rules []validationRule) {
+	if omitEmpty && (!v.IsValid() || v.IsZero()) {
+		return
+	}
+	for _, rule := range rules {
+		if msg := rule.check(v); msg != "" {
+			vd.errs = append(vd.errs, ValidationError{Field: path, Rule: rule.name, Message: msg})
+		}
+	}
+}
+
+// value validate nested structs, or elements of slice and map if dive
+func (vd *validation) value(path string, v reflect.Value, dive bool, elemRules []validationRule) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == typeTime {
+			return
+		}
+		vd.structure(path, v)
+	case reflect.Slice, reflect.Array:
+		if !dive {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			vd.rules(p, v.Index(i), false, elemRules)
+			vd.value(p, v.Index(i), false, nil)
+		}
+	case reflect.Map:
+		if !dive {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			p := path + "[" + fmt.Sprint(iter.Key().Interface()) + "]"
+			vd.rules(p, iter.Value(), false, elemRules)
+			vd.value(p, iter.Value(), false, nil)
+		}
+	}
+}
+
+func (vd *validation) structure(path string, v reflect.Value) {
+	for _, f := range validationFieldsOf(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			// nil embedded pointer
+			continue
+		}
+		p := f.name
+		if path != "" {
+			p = path + "." + f.name
+		}
+		vd.rules(p, fv, f.omitEmpty, f.rules)
+		vd.value(p, fv, f.dive, f.elemRules)
+	}
+}
+
+// Validate validate a struct with "validate" tags, and [Validator] if implemented
+//
+// Supported rules are "required", "omitempty", "min", "max", "len", "regexp", "oneof", "email", "uuid" and "dive".
+// Nested structs are always validated, elements of slices and maps are validated with "dive", and rules after
+// "dive" apply to each element. "regexp" must be the last rule, it consumes the rest of tag.
+//
+// All violations are collected into a single [HaltError] with [http.StatusBadRequest] and extra "errors"
+//
+// example:
+//
+//	type CreateUserRequest struct {
+//		Name  string   `json:"name" validate:"required,max=32"`
+//		Email string   `json:"email" validate:"omitempty,email"`
+//		Role  string   `json:"role" validate:"oneof=admin user"`
+//		Tags  []string `json:"tags" validate:"max=5,dive,min=1"`
+//	}
+func Validate(data any) error {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	vd := &validation{}
+	vd.structure("", v)
+
+	if len(vd.errs) > 0 {
+		return NewHaltError(
+			errors.New("validation failed: "+vd.errs[0].Field+" "+vd.errs[0].Message),
+			HaltWithBadRequest(),
+			HaltWithExtra(HaltExtraKeyErrors, vd.errs),
+		)
+	}
+
+	if vv, ok := data.(Validator); ok {
+		if err := vv.Validate(); err != nil {
+			if _, ok := err.(withStatusCode); ok {
+				return err
+			}
+			return NewHaltError(err, HaltWithBadRequest())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package summer
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type validateTestItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type validateTestRequest struct {
+	Name     string             `json:"name" validate:"required,max=4"`
+	Age      int                `json:"age" validate:"min=18,max=60"`
+	Code     string             `json:"code" validate:"len=3"`
+	Role     string             `json:"role" validate:"oneof=admin user"`
+	Email    string             `json:"email" validate:"omitempty,email"`
+	ID       string             `json:"id" validate:"omitempty,uuid"`
+	Slug     string             `json:"slug" validate:"regexp=^[a-z]{1,3}$"`
+	Nickname *string            `json:"nickname" validate:"min=2"`
+	Tags     []string           `json:"tags" validate:"max=2,dive,min=2"`
+	Items    []validateTestItem `json:"items" validate:"dive"`
+	Owner    validateTestItem   `json:"owner"`
+	Extra    map[string]int     `json:"extra" validate:"dive,max=1"`
+	Password string             `json:"password"`
+	Confirm  string             `json:"confirm"`
+}
+
+func (r validateTestRequest) Validate() error {
+	if r.Password != r.Confirm {
+		return errors.New("password mismatch")
+	}
+	return nil
+}
+
+func TestParseValidationRules(t *testing.T) {
+	omitEmpty, rules, dive, elemRules, err := parseValidationRules("omitempty,min=1,dive,regexp=^a,b$")
+	require.NoError(t, err)
+	require.True(t, omitEmpty)
+	require.True(t, dive)
+	require.Len(t, rules, 1)
+	require.Len(t, elemRules, 1)
+	require.Equal(t, "^a,b$", elemRules[0].param)
+
+	_, _, _, _, err = parseValidationRules("min=a")
+	require.Error(t, err)
+	_, _, _, _, err = parseValidationRules("unknown")
+	require.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	valid := validateTestRequest{
+		Name:  "abc",
+		Age:   20,
+		Code:  "abc",
+		Role:  "admin",
+		Email: "a@example.com",
+		ID:    "123e4567-e89b-12d3-a456-426614174000",
+		Slug:  "ab",
+		Tags:  []string{"aa"},
+		Items: []validateTestItem{{Name: "a"}},
+		Owner: validateTestItem{Name: "b"},
+	}
+	require.NoError(t, Validate(&valid))
+
+	short := "a"
+	invalid := validateTestRequest{
+		Name:     "abcde",
+		Age:      10,
+		Code:     "ab",
+		Role:     "root",
+		Email:    "bad",
+		ID:       "bad",
+		Slug:     "ABC",
+		Nickname: &short,
+		Tags:     []string{"a", "bb", "cc"},
+		Items:    []validateTestItem{{}},
+		Extra:    map[string]int{"k": 2},
+	}
+	err := Validate(&invalid)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, StatusCodeFromError(err))
+	require.Equal(t, []ValidationError{
+		{Field: "name", Rule: "max", Message: "length must be at most 4"},
+		{Field: "age", Rule: "min", Message: "must be at least 18"},
+		{Field: "code", Rule: "len", Message: "length must be 3"},
+		{Field: "role", Rule: "oneof", Message: "must be one of admin, user"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "id", Rule: "uuid", Message: "must be a valid uuid"},
+		{Field: "slug", Rule: "regexp", Message: "must match ^[a-z]{1,3}$"},
+		{Field: "nickname", Rule: "min", Message: "length must be at least 2"},
+		{Field: "tags", Rule: "max", Message: "length must be at most 2"},
+		{Field: "tags[0]", Rule: "min", Message: "length must be at least 2"},
+		{Field: "items[0].name", Rule: "required", Message: "is required"},
+		{Field: "owner.name", Rule: "required", Message: "is required"},
+		{Field: "extra[k]", Rule: "max", Message: "must be at most 1"},
+	}, BodyFromError(err)[HaltExtraKeyErrors])
+
+	valid.Password = "a"
+	err = Validate(&valid)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, StatusCodeFromError(err))
+	require.Equal(t, "password mismatch", err.Error())
+}
+
+func TestBindValidate(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/test", func(c Context) {
+		Bind[struct {
+			Name  string `json:"query_name" validate:"required"`
+			Limit int    `json:"query_limit" validate:"max=10"`
+		}](c)
+		c.Text("OK")
+	})
+
+	rw, req := httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test?limit=20", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusBadRequest, rw.Code)
+	require.JSONEq(t, `{
+		"message": "validation failed: query_name is required",
+		"errors": [
+			{"field": "query_name", "rule": "required", "message": "is required"},
+			{"field": "query_limit", "rule": "max", "message": "must be at most 10"}
+		]
+	}`, rw.Body.String())
+
+	rw, req = httptest.NewRecorder(), httptest.NewRequest("GET", "https://example.com/test?name=a&limit=1", nil)
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+}
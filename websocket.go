@@ -0,0 +1,524 @@
+package summer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocketMessageType type of a WebSocket data message
+type WebSocketMessageType int
+
+const (
+	WebSocketText   WebSocketMessageType = 1
+	WebSocketBinary WebSocketMessageType = 2
+)
+
+// WebSocket close codes defined by RFC 6455
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseAbnormal        = 1006
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseMessageTooBig   = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// WebSocketCloseError returned by [WebSocketConn.ReadMessage] when connection is closed by peer,
+// or by a protocol violation
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	s := "websocket: close " + strconv.Itoa(e.Code)
+	if e.Reason != "" {
+		s += " " + e.Reason
+	}
+	return s
+}
+
+type webSocketOptions struct {
+	subprotocols []string
+	readLimit    int64
+	pingInterval time.Duration
+	writeTimeout time.Duration
+	checkOrigin  func(req *http.Request) bool
+}
+
+// WebSocketOption configuration function for [Context.Upgrade]
+type WebSocketOption func(o *webSocketOptions)
+
+// WebSocketWithSubprotocols a [WebSocketOption] setting supported subprotocols in order of preference
+func WebSocketWithSubprotocols(protocols ...string) WebSocketOption {
+	return func(o *webSocketOptions) {
+		o.subprotocols = protocols
+	}
+}
+
+// WebSocketWithReadLimit a [WebSocketOption] setting maximum bytes of an incoming message, default to 1 MiB,
+// a larger message closes the connection with [WebSocketCloseMessageTooBig]
+func WebSocketWithReadLimit(n int64) WebSocketOption {
+	return func(o *webSocketOptions) {
+		o.readLimit = n
+	}
+}
+
+// WebSocketWithPingInterval a [WebSocketOption] setting interval of pings sent to client, default to 30 seconds,
+// a client not responding in two intervals is considered gone
+//
+// A value <= 0 means disabled
+func WebSocketWithPingInterval(d time.Duration) WebSocketOption {
+	return func(o *webSocketOptions) {
+		o.pingInterval = d
+	}
+}
+
+// WebSocketWithWriteTimeout a [WebSocketOption] setting timeout of writing a single message, default to 10 seconds
+//
+// A value <= 0 means disabled
+func WebSocketWithWriteTimeout(d time.Duration) WebSocketOption {
+	return func(o *webSocketOptions) {
+		o.writeTimeout = d
+	}
+}
+
+// WebSocketWithCheckOrigin a [WebSocketOption] setting function checking Origin header of handshake,
+// a request failing the check is rejected with [http.StatusForbidden]
+//
+// By default, a request with Origin header is rejected if host of Origin differs from Host header,
+// preventing cross-site WebSocket hijacking with cookies of user
+func WebSocketWithCheckOrigin(fn func(req *http.Request) bool) WebSocketOption {
+	return func(o *webSocketOptions) {
+		o.checkOrigin = fn
+	}
+}
+
+// webSocketSameOrigin default origin check, requests without Origin header are from non-browser clients
+func webSocketSameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, req.Host)
+}
+
+// WebSocketConn a server side WebSocket connection created by [Context.Upgrade]
+//
+// ReadMessage must not be called concurrently, others are safe for concurrent use.
+// The connection is closed in [Context.Perform], it must not be used after handler returns
+type WebSocketConn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	opts        *webSocketOptions
+	subprotocol string
+
+	wmu       sync.Mutex
+	closeSent bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Subprotocol returns the negotiated subprotocol, or empty
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// writeFrame write a single unmasked frame
+func (ws *WebSocketConn) writeFrame(op byte, payload []byte) (err error) {
+	ws.wmu.Lock()
+	defer ws.wmu.Unlock()
+
+	if ws.closeSent {
+		return net.ErrClosed
+	}
+	if op == wsOpClose {
+		ws.closeSent = true
+	}
+
+	if ws.opts.writeTimeout > 0 {
+		_ = ws.conn.SetWriteDeadline(time.Now().Add(ws.opts.writeTimeout))
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | op
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	_, err = ws.conn.Write(append(header, payload...))
+	return
+}
+
+// WriteMessage write a text or binary message
+func (ws *WebSocketConn) WriteMessage(typ WebSocketMessageType, data []byte) error {
+	if typ != WebSocketText && typ != WebSocketBinary {
+		return errors.New("websocket: invalid message type " + strconv.Itoa(int(typ)))
+	}
+	return ws.writeFrame(byte(typ), data)
+}
+
+// WriteText alias to [WebSocketConn.WriteMessage] with [WebSocketText]
+func (ws *WebSocketConn) WriteText(s string) error {
+	return ws.WriteMessage(WebSocketText, []byte(s))
+}
+
+// Ping send a ping, payload must not exceed 125 bytes
+func (ws *WebSocketConn) Ping(payload []byte) error {
+	if len(payload) > 125 {
+		return errors.New("websocket: control frame payload too large")
+	}
+	return ws.writeFrame(wsOpPing, payload)
+}
+
+// Close send a close frame with code and reason, and close the underlying connection
+func (ws *WebSocketConn) Close(code int, reason string) error {
+	err := ws.sendClose(code, reason)
+	ws.shutdown()
+	return err
+}
+
+func (ws *WebSocketConn) sendClose(code int, reason string) error {
+	var payload []byte
+	if code != WebSocketCloseNoStatus {
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+	return ws.writeFrame(wsOpClose, payload)
+}
+
+// shutdown stop pinging and close the underlying connection
+func (ws *WebSocketConn) shutdown() {
+	ws.stopOnce.Do(func() {
+		close(ws.stop)
+		_ = ws.conn.Close()
+	})
+	ws.wg.Wait()
+}
+
+// fail send a close frame for a protocol violation, and returns it as error
+func (ws *WebSocketConn) fail(code int, reason string) error {
+	_ = ws.sendClose(code, reason)
+	return &WebSocketCloseError{Code: code, Reason: reason}
+}
+
+// ReadMessage read a complete text or binary message, pings are replied and pongs are discarded automatically
+//
+// A [*WebSocketCloseError] is returned if connection is closed by peer or a protocol violation occurred
+func (ws *WebSocketConn) ReadMessage() (typ WebSocketMessageType, data []byte, err error) {
+	for {
+		if ws.opts.pingInterval > 0 {
+			_ = ws.conn.SetReadDeadline(time.Now().Add(ws.opts.pingInterval * 2))
+		}
+
+		var head [2]byte
+		if _, err = io.ReadFull(ws.br, head[:]); err != nil {
+			return
+		}
+
+		var (
+			fin    = head[0]&0x80 != 0
+			op     = head[0] & 0x0f
+			masked = head[1]&0x80 != 0
+			length = int64(head[1] & 0x7f)
+		)
+
+		if head[0]&0x70 != 0 {
+			err = ws.fail(WebSocketCloseProtocolError, "reserved bits set")
+			return
+		}
+		if !masked {
+			err = ws.fail(WebSocketCloseProtocolError, "frame not masked")
+			return
+		}
+
+		switch length {
+		case 126:
+			var buf [2]byte
+			if _, err = io.ReadFull(ws.br, buf[:]); err != nil {
+				return
+			}
+			length = int64(binary.BigEndian.Uint16(buf[:]))
+		case 127:
+			var buf [8]byte
+			if _, err = io.ReadFull(ws.br, buf[:]); err != nil {
+				return
+			}
+			if buf[0]&0x80 != 0 {
+				err = ws.fail(WebSocketCloseProtocolError, "invalid payload length")
+				return
+			}
+			length = int64(binary.BigEndian.Uint64(buf[:]))
+		}
+
+		control := op&0x08 != 0
+		if control && (!fin || length > 125) {
+			err = ws.fail(WebSocketCloseProtocolError, "invalid control frame")
+			return
+		}
+
+		switch op {
+		case wsOpContinuation:
+			if typ == 0 {
+				err = ws.fail(WebSocketCloseProtocolError, "unexpected continuation frame")
+				return
+			}
+		case wsOpText, wsOpBinary:
+			if typ != 0 {
+				err = ws.fail(WebSocketCloseProtocolError, "unexpected data frame")
+				return
+			}
+			typ = WebSocketMessageType(op)
+		case wsOpClose, wsOpPing, wsOpPong:
+		default:
+			err = ws.fail(WebSocketCloseProtocolError, "unknown opcode")
+			return
+		}
+
+		if !control && int64(len(data))+length > ws.opts.readLimit {
+			err = ws.fail(WebSocketCloseMessageTooBig, "message too big")
+			return
+		}
+
+		var mask [4]byte
+		if _, err = io.ReadFull(ws.br, mask[:]); err != nil {
+			return
+		}
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(ws.br, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch op {
+		case wsOpPing:
+			if err = ws.writeFrame(wsOpPong, payload); err != nil {
+				return
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			ce := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+			switch {
+			case len(payload) == 1:
+				err = ws.fail(WebSocketCloseProtocolError, "invalid close frame")
+				return
+			case len(payload) >= 2:
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Reason = string(payload[2:])
+				if !utf8.ValidString(ce.Reason) {
+					err = ws.fail(WebSocketCloseInvalidPayload, "invalid close reason")
+					return
+				}
+			}
+			// echo the close code
+			_ = ws.sendClose(ce.Code, "")
+			err = ce
+			return
+		}
+
+		data = append(data, payload...)
+
+		if fin {
+			if typ == WebSocketText && !utf8.Valid(data) {
+				err = ws.fail(WebSocketCloseInvalidPayload, "invalid utf-8")
+				return
+			}
+			return
+		}
+	}
+}
+
+// ping send pings periodically until connection is closed
+func (ws *WebSocketConn) ping() {
+	defer ws.wg.Done()
+
+	t := time.NewTicker(ws.opts.pingInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ws.stop:
+			return
+		case <-t.C:
+			if ws.Ping(nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+// webSocketAccept compute Sec-WebSocket-Accept from Sec-WebSocket-Key
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContainsToken check if a comma separated header contains a token, case-insensitively
+func headerContainsToken(h http.Header, name string, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *basicContext) Upgrade(opts ...WebSocketOption) *WebSocketConn {
+	if c.ws != nil {
+		return c.ws
+	}
+
+	o := &webSocketOptions{
+		readLimit:    1 << 20,
+		pingInterval: time.Second * 30,
+		writeTimeout: time.Second * 10,
+		checkOrigin:  webSocketSameOrigin,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	req := c.req
+
+	if req.Method != http.MethodGet ||
+		!headerContainsToken(req.Header, "Connection", "upgrade") ||
+		!headerContainsToken(req.Header, "Upgrade", "websocket") {
+		HaltString("websocket: not a websocket handshake", HaltWithBadRequest())
+	}
+	if req.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.rw.Header().Set("Sec-WebSocket-Version", "13")
+		HaltString("websocket: unsupported version", HaltWithStatusCode(http.StatusUpgradeRequired))
+	}
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if buf, err := base64.StdEncoding.DecodeString(key); err != nil || len(buf) != 16 {
+		HaltString("websocket: invalid Sec-WebSocket-Key", HaltWithBadRequest())
+	}
+	if o.checkOrigin != nil && !o.checkOrigin(req) {
+		HaltString("websocket: origin not allowed", HaltWithStatusCode(http.StatusForbidden))
+	}
+
+	var subprotocol string
+	for _, p := range o.subprotocols {
+		if headerContainsToken(req.Header, "Sec-WebSocket-Protocol", p) {
+			subprotocol = p
+			break
+		}
+	}
+
+	// hijacked connections are not tracked once [App.Run] starts draining
+	if d := drainerFromContext(req.Context()); d != nil && d.stopping() {
+		HaltString("websocket: server shutting down", HaltWithStatusCode(http.StatusServiceUnavailable))
+	}
+
+	hj, ok := c.rw.(http.Hijacker)
+	if !ok {
+		HaltString("websocket: response does not implement http.Hijacker")
+	}
+
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		raise(err)
+	}
+
+	// response is written manually from now on
+	c.sendOnce.Do(func() {})
+
+	// clear deadlines set by http.Server
+	_ = conn.SetDeadline(time.Time{})
+
+	h := c.rw.Header().Clone()
+	h.Del("Content-Length")
+	h.Del("Content-Type")
+	h.Del("X-Content-Type-Options")
+	h.Set("Upgrade", "websocket")
+	h.Set("Connection", "Upgrade")
+	h.Set("Sec-WebSocket-Accept", webSocketAccept(key))
+	if subprotocol != "" {
+		h.Set("Sec-WebSocket-Protocol", subprotocol)
+	}
+
+	_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	_ = h.Write(brw)
+	_, _ = brw.WriteString("\r\n")
+	if err = brw.Flush(); err != nil {
+		_ = conn.Close()
+		raise(err)
+	}
+
+	c.ws = &WebSocketConn{
+		conn:        conn,
+		br:          brw.Reader,
+		opts:        o,
+		subprotocol: subprotocol,
+		stop:        make(chan struct{}),
+	}
+
+	// long-lived connections should not occupy concurrency slots of normal requests
+	if release, ok := req.Context().Value(contextKeyConcurrencyRelease).(func()); ok {
+		release()
+	}
+
+	// closed with [WebSocketCloseGoingAway] when [App.Run] shuts down
+	c.watchDrain()
+	if d := drainerFromContext(c.req.Context()); d != nil && !d.track(c.ws) {
+		// stopped after the check above
+		_ = c.ws.sendClose(WebSocketCloseGoingAway, "server shutting down")
+	}
+
+	if o.pingInterval > 0 {
+		c.ws.wg.Add(1)
+		go c.ws.ping()
+	}
+
+	return c.ws
+}
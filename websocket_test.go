@@ -0,0 +1,214 @@
+package summer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testWebSocketClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialTestWebSocket(t *testing.T, base string, path string, headers ...string) (*testWebSocketClient, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(base, "http://"))
+	require.NoError(t, err)
+	_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+
+	_, err = io.WriteString(conn, "GET "+path+" HTTP/1.1\r\n"+
+		"Host: example.com\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Protocol: chat, superchat\r\n"+
+		strings.Join(append(headers, ""), "\r\n")+"\r\n")
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	return &testWebSocketClient{conn: conn, br: br}, res
+}
+
+func (c *testWebSocketClient) write(t *testing.T, fin bool, op byte, payload []byte) {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	buf := []byte{b0, 0x80 | byte(len(payload))}
+	mask := []byte{1, 2, 3, 4}
+	buf = append(buf, mask...)
+	for i, b := range payload {
+		buf = append(buf, b^mask[i%4])
+	}
+	_, err := c.conn.Write(buf)
+	require.NoError(t, err)
+}
+
+func (c *testWebSocketClient) read(t *testing.T) (op byte, payload []byte) {
+	var head [2]byte
+	_, err := io.ReadFull(c.br, head[:])
+	require.NoError(t, err)
+	require.Zero(t, head[1]&0x80, "server frames must not be masked")
+	n := int(head[1] & 0x7f)
+	require.Less(t, n, 126)
+	payload = make([]byte, n)
+	_, err = io.ReadFull(c.br, payload)
+	require.NoError(t, err)
+	return head[0] & 0x0f, payload
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", webSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestContextUpgrade(t *testing.T) {
+	closed := make(chan error, 1)
+
+	a := Basic(WithConcurrency(1))
+	a.HandleFunc("/ws", func(c Context) {
+		c.SetCookie("session", "1")
+		ws := c.Upgrade(WebSocketWithSubprotocols("superchat"), WebSocketWithPingInterval(0))
+		require.Equal(t, "superchat", ws.Subprotocol())
+		for {
+			typ, data, err := ws.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			require.NoError(t, ws.WriteMessage(typ, data))
+		}
+	})
+	a.HandleFunc("/hello", func(c Context) {
+		c.Text("hello")
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	c, res := dialTestWebSocket(t, s.URL, "/ws")
+	defer c.conn.Close()
+
+	require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", res.Header.Get("Sec-WebSocket-Accept"))
+	require.Equal(t, "superchat", res.Header.Get("Sec-WebSocket-Protocol"))
+	require.Contains(t, res.Header.Get("Set-Cookie"), "session=1")
+
+	// concurrency slot is released
+	hres, err := http.Get(s.URL + "/hello")
+	require.NoError(t, err)
+	_ = hres.Body.Close()
+	require.Equal(t, http.StatusOK, hres.StatusCode)
+
+	c.write(t, true, wsOpText, []byte("hello"))
+	op, payload := c.read(t)
+	require.Equal(t, byte(wsOpText), op)
+	require.Equal(t, "hello", string(payload))
+
+	// ping interleaved with a fragmented message
+	c.write(t, false, wsOpBinary, []byte("wor"))
+	c.write(t, true, wsOpPing, []byte("p"))
+	c.write(t, true, wsOpContinuation, []byte("ld"))
+
+	op, payload = c.read(t)
+	require.Equal(t, byte(wsOpPong), op)
+	require.Equal(t, "p", string(payload))
+
+	op, payload = c.read(t)
+	require.Equal(t, byte(wsOpBinary), op)
+	require.Equal(t, "world", string(payload))
+
+	c.write(t, true, wsOpClose, append(binary.BigEndian.AppendUint16(nil, WebSocketCloseGoingAway), "bye"...))
+	op, payload = c.read(t)
+	require.Equal(t, byte(wsOpClose), op)
+	require.Equal(t, uint16(WebSocketCloseGoingAway), binary.BigEndian.Uint16(payload))
+
+	err = <-closed
+	require.Equal(t, &WebSocketCloseError{Code: WebSocketCloseGoingAway, Reason: "bye"}, err)
+}
+
+func TestContextUpgradeFailure(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/ws", func(c Context) {
+		ws := c.Upgrade(WebSocketWithReadLimit(4))
+		if _, _, err := ws.ReadMessage(); err != nil {
+			return
+		}
+		panic("boom")
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	// not a handshake
+	res, err := http.Get(s.URL + "/ws")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// message too big
+	c, res := dialTestWebSocket(t, s.URL, "/ws")
+	require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	c.write(t, true, wsOpText, []byte("hello"))
+	op, payload := c.read(t)
+	require.Equal(t, byte(wsOpClose), op)
+	require.Equal(t, uint16(WebSocketCloseMessageTooBig), binary.BigEndian.Uint16(payload))
+	_ = c.conn.Close()
+
+	// handler panicked
+	c, _ = dialTestWebSocket(t, s.URL, "/ws")
+	c.write(t, true, wsOpText, []byte("ok"))
+	op, payload = c.read(t)
+	require.Equal(t, byte(wsOpClose), op)
+	require.Equal(t, uint16(WebSocketCloseInternalError), binary.BigEndian.Uint16(payload))
+	_, err = c.br.ReadByte()
+	require.Equal(t, io.EOF, err)
+	_ = c.conn.Close()
+}
+
+func TestContextUpgradeOrigin(t *testing.T) {
+	a := Basic()
+	a.HandleFunc("/ws", func(c Context) {
+		c.Upgrade(WebSocketWithPingInterval(0))
+	})
+	a.HandleFunc("/ws/any", func(c Context) {
+		c.Upgrade(WebSocketWithPingInterval(0), WebSocketWithCheckOrigin(func(req *http.Request) bool {
+			return req.Header.Get("Origin") == "https://trusted.example.org"
+		}))
+	})
+
+	s := httptest.NewServer(a)
+	defer s.Close()
+
+	for _, item := range []struct {
+		path   string
+		origin string
+		code   int
+	}{
+		{"/ws", "", http.StatusSwitchingProtocols},
+		{"/ws", "https://example.com", http.StatusSwitchingProtocols},
+		{"/ws", "https://EXAMPLE.com", http.StatusSwitchingProtocols},
+		{"/ws", "https://evil.example.org", http.StatusForbidden},
+		{"/ws", "https://example.com:8443", http.StatusForbidden},
+		{"/ws", "null", http.StatusForbidden},
+		{"/ws/any", "https://trusted.example.org", http.StatusSwitchingProtocols},
+		{"/ws/any", "https://example.com", http.StatusForbidden},
+	} {
+		var headers []string
+		if item.origin != "" {
+			headers = append(headers, "Origin: "+item.origin)
+		}
+		c, res := dialTestWebSocket(t, s.URL, item.path, headers...)
+		require.Equal(t, item.code, res.StatusCode, item.path+" "+item.origin)
+		_ = c.conn.Close()
+	}
+}
@@ -0,0 +1,116 @@
+package summer
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// regexpYAMLPlain strings safe to be written without quotes
+var regexpYAMLPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// EncodeYAML encoder for application/yaml, value is converted with json tags and written in block style
+func EncodeYAML(v any) (buf []byte, err error) {
+	var g any
+	if g, err = genericValue(v); err != nil {
+		return
+	}
+	sb := &strings.Builder{}
+	switch g := g.(type) {
+	case map[string]any:
+		if len(g) == 0 {
+			sb.WriteString("{}\n")
+		} else {
+			encodeYAMLMap(sb, g, 0, false)
+		}
+	case []any:
+		if len(g) == 0 {
+			sb.WriteString("[]\n")
+		} else {
+			encodeYAMLSlice(sb, g, 0)
+		}
+	default:
+		sb.WriteString(yamlScalar(g) + "\n")
+	}
+	buf = []byte(sb.String())
+	return
+}
+
+// yamlScalar format a scalar, strings are double-quoted with JSON escaping unless safe
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		default:
+			if regexpYAMLPlain.MatchString(v) {
+				return v
+			}
+		}
+		buf, _ := json.Marshal(v)
+		return string(buf)
+	}
+	return "null"
+}
+
+// encodeYAMLValue write a value after "key:" or "-", nested collections start on a new line
+func encodeYAMLValue(sb *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			sb.WriteString(" {}\n")
+			return
+		}
+		sb.WriteString("\n")
+		encodeYAMLMap(sb, v, indent, false)
+	case []any:
+		if len(v) == 0 {
+			sb.WriteString(" []\n")
+			return
+		}
+		sb.WriteString("\n")
+		encodeYAMLSlice(sb, v, indent)
+	default:
+		sb.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+// encodeYAMLMap write entries of a non-empty map, the first entry is not indented if inline
+func encodeYAMLMap(sb *strings.Builder, m map[string]any, indent int, inline bool) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if i > 0 || !inline {
+			sb.WriteString(strings.Repeat(" ", indent))
+		}
+		sb.WriteString(yamlScalar(k) + ":")
+		encodeYAMLValue(sb, m[k], indent+2)
+	}
+}
+
+// encodeYAMLSlice write items of a non-empty slice, a map item starts on the same line as "-"
+func encodeYAMLSlice(sb *strings.Builder, s []any, indent int) {
+	for _, item := range s {
+		sb.WriteString(strings.Repeat(" ", indent) + "-")
+		if m, ok := item.(map[string]any); ok && len(m) > 0 {
+			sb.WriteString(" ")
+			encodeYAMLMap(sb, m, indent+2, true)
+			continue
+		}
+		encodeYAMLValue(sb, item, indent+2)
+	}
+}